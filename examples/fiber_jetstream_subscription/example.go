@@ -1,12 +1,12 @@
 package main
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/nats-io/nats.go"
 	"github.com/todesdev/promnatsfiber"
 	"github.com/todesdev/promnatsfiber/middleware"
 	"log"
-	"time"
 )
 
 func main() {
@@ -50,16 +50,10 @@ func subscribeToJetStreamMessages(nc *nats.Conn) {
 		log.Fatal(err)
 	}
 
-	// Process messages
-	for {
-		msg, err := sub.NextMsg(10 * time.Second)
-		if err != nil {
-			log.Println("Failed to receive a message:", err)
-			continue
-		}
-
-		// Example of instrumented processing a JetStream message
-		middleware.WrapProcessJetStreamMessage(jetStreamMessageHandler)(msg)
+	// Example of instrumented processing of JetStream messages by 4 workers
+	err = middleware.RunWorkers(context.Background(), sub, 4, middleware.WrapProcessJetStreamMessage(jetStreamMessageHandler))
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
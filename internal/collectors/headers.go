@@ -0,0 +1,135 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// NatsHeaderLabelDefaultValue is the label value of messages without the header, unless
+	// another default is configured.
+	NatsHeaderLabelDefaultValue = "unknown"
+	// NatsHeaderLabelOverflowValue replaces header values beyond the cardinality limit.
+	NatsHeaderLabelOverflowValue = "other"
+	// NatsDefaultHeaderLabelMaxValues is the cardinality limit used when none is configured.
+	NatsDefaultHeaderLabelMaxValues = 50
+)
+
+var headerLabeler *HeaderLabeler
+
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// HeaderLabel reads the value of a label of the processed and published message metrics from
+// a message header.
+type HeaderLabel struct {
+	Header    string
+	Label     string
+	Default   string
+	MaxValues int
+}
+
+type headerLabelValues struct {
+	HeaderLabel
+	seen map[string]struct{}
+}
+
+// HeaderLabeler extracts label values from message headers, bounding the number of distinct
+// values of each label.
+type HeaderLabeler struct {
+	mu     sync.Mutex
+	labels []headerLabelValues
+}
+
+// ValidateHeaderLabels checks that the header labels have a header and a valid, unique label name
+// that does not collide with the subject and type labels of the message metrics.
+func ValidateHeaderLabels(labels []HeaderLabel) error {
+	names := map[string]bool{NatsSubjectLabel: true, NatsTypeLabel: true}
+	for _, label := range labels {
+		switch {
+		case label.Header == "":
+			return fmt.Errorf("header label %q has no header", label.Label)
+		case !labelNamePattern.MatchString(label.Label) || strings.HasPrefix(label.Label, "__"):
+			return fmt.Errorf("header label %q of header %q is not a valid Prometheus label name", label.Label, label.Header)
+		case names[label.Label]:
+			return fmt.Errorf("header label %q of header %q collides with another label of the message metrics", label.Label, label.Header)
+		}
+		names[label.Label] = true
+	}
+
+	return nil
+}
+
+// NewHeaderLabeler creates a HeaderLabeler from the configured header labels, applying the
+// default value and cardinality limit where none is configured.
+func NewHeaderLabeler(labels []HeaderLabel) *HeaderLabeler {
+	labeler := &HeaderLabeler{}
+	for _, label := range labels {
+		if label.Default == "" {
+			label.Default = NatsHeaderLabelDefaultValue
+		}
+		if label.MaxValues <= 0 {
+			label.MaxValues = NatsDefaultHeaderLabelMaxValues
+		}
+
+		labeler.labels = append(labeler.labels, headerLabelValues{
+			HeaderLabel: label,
+			seen:        make(map[string]struct{}),
+		})
+	}
+
+	headerLabeler = labeler
+
+	return headerLabeler
+}
+
+func GetHeaderLabeler() (*HeaderLabeler, error) {
+	if headerLabeler == nil {
+		return nil, errors.New("headerLabeler is nil")
+	}
+	return headerLabeler, nil
+}
+
+// Names returns the names of the header labels in the order of their values.
+func (l *HeaderLabeler) Names() []string {
+	names := make([]string, 0, len(l.labels))
+	for _, label := range l.labels {
+		names = append(names, label.Label)
+	}
+
+	return names
+}
+
+// Values returns the label values read from a message header. Once a label has reached its
+// cardinality limit, values that have not been seen before are replaced by the overflow value.
+func (l *HeaderLabeler) Values(header nats.Header) []string {
+	if len(l.labels) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := make([]string, 0, len(l.labels))
+	for _, label := range l.labels {
+		value := header.Get(label.Header)
+		if value == "" {
+			values = append(values, label.Default)
+			continue
+		}
+
+		if _, ok := label.seen[value]; !ok {
+			if len(label.seen) >= label.MaxValues {
+				values = append(values, NatsHeaderLabelOverflowValue)
+				continue
+			}
+			label.seen[value] = struct{}{}
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
@@ -0,0 +1,30 @@
+package collectors
+
+import (
+	"testing"
+)
+
+func TestValidateHeaderLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  []HeaderLabel
+		wantErr bool
+	}{
+		{"valid", []HeaderLabel{{Header: "Event-Type", Label: "event_type"}, {Header: "Tenant", Label: "tenant"}}, false},
+		{"missing header", []HeaderLabel{{Label: "event_type"}}, true},
+		{"invalid name", []HeaderLabel{{Header: "Event-Type", Label: "event-type"}}, true},
+		{"leading digit", []HeaderLabel{{Header: "Event-Type", Label: "1type"}}, true},
+		{"reserved prefix", []HeaderLabel{{Header: "Event-Type", Label: "__type"}}, true},
+		{"subject collision", []HeaderLabel{{Header: "Subject", Label: NatsSubjectLabel}}, true},
+		{"type collision", []HeaderLabel{{Header: "Type", Label: NatsTypeLabel}}, true},
+		{"duplicate", []HeaderLabel{{Header: "Tenant", Label: "tenant"}, {Header: "Tenant-Id", Label: "tenant"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHeaderLabels(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHeaderLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
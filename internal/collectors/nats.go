@@ -6,10 +6,19 @@ import (
 )
 
 type AsyncMessageBrokerMetricsCollector interface {
-	IncProcessedMessageCount(subject, messageType string)
-	ObserveMessageProcessingDuration(subject, messageType string, duration float64)
-	IncPublishedMessageCount(subject, messageType string)
-	ObserveMessagePublishingDuration(subject, messageType string, duration float64)
+	IncProcessedMessageCount(subject, messageType string, headerLabelValues ...string)
+	ObserveMessageProcessingDuration(subject, messageType string, duration float64, headerLabelValues ...string)
+	IncPublishedMessageCount(subject, messageType string, headerLabelValues ...string)
+	ObserveMessagePublishingDuration(subject, messageType string, duration float64, headerLabelValues ...string)
+	IncPublishErrorCount(subject, messageType, reason string)
+	IncPubAckCount(stream, duplicate string)
+	SetPubAckSequence(stream string, sequence float64)
+	ObserveMessageEndToEndLatency(subject, sourceService string, latency float64)
+	ObserveProcessedMessageSize(subject, messageType string, size float64, headerLabelValues ...string)
+	AddProcessedMessageBytes(subject, messageType string, size float64, headerLabelValues ...string)
+	ObservePublishedMessageSize(subject, messageType string, size float64, headerLabelValues ...string)
+	AddPublishedMessageBytes(subject, messageType string, size float64, headerLabelValues ...string)
+	GetServiceName() string
 }
 
 const (
@@ -25,30 +34,84 @@ const (
 	NatsPublishingMessageDuration     = "publishing_message_duration_seconds"
 	NatsPublishingMessageDurationHelp = "Duration of NATS message publishing."
 
-	NatsSubjectLabel = "subject"
-	NatsTypeLabel    = "type"
+	NatsPublishErrorsTotal     = "publish_errors_total"
+	NatsPublishErrorsTotalHelp = "Total number of failed NATS message publishing attempts."
+
+	NatsJetStreamPubAcksTotal       = "jetstream_pub_acks_total"
+	NatsJetStreamPubAcksTotalHelp   = "Total number of JetStream publish acknowledgements."
+	NatsJetStreamPubAckSequence     = "jetstream_pub_ack_sequence"
+	NatsJetStreamPubAckSequenceHelp = "Stream sequence of the last acknowledged JetStream publish."
+
+	NatsMessageEndToEndLatency     = "message_end_to_end_latency_seconds"
+	NatsMessageEndToEndLatencyHelp = "Latency between NATS message publishing and the start of its processing."
+
+	NatsProcessedMessageSizeBytes     = "processed_message_size_bytes"
+	NatsProcessedMessageSizeBytesHelp = "Size of processed NATS messages including headers."
+	NatsProcessedBytesTotal           = "processed_bytes_total"
+	NatsProcessedBytesTotalHelp       = "Total number of bytes of processed NATS messages including headers."
+	NatsPublishedMessageSizeBytes     = "published_message_size_bytes"
+	NatsPublishedMessageSizeBytesHelp = "Size of published NATS messages including headers."
+	NatsPublishedBytesTotal           = "published_bytes_total"
+	NatsPublishedBytesTotalHelp       = "Total number of bytes of published NATS messages including headers."
+
+	NatsSubjectLabel       = "subject"
+	NatsTypeLabel          = "type"
+	NatsSourceServiceLabel = "source_service"
+	NatsReasonLabel        = "reason"
+	NatsStreamLabel        = "stream"
+	NatsDuplicateLabel     = "duplicate"
 
 	NatsSimpleMessageType    = "simple"
 	NatsJetStreamMessageType = "jetstream"
+	NatsMicroMessageType     = "micro"
+
+	NatsPublishErrorTimeout          = "timeout"
+	NatsPublishErrorNoResponders     = "no_responders"
+	NatsPublishErrorStreamNotFound   = "stream_not_found"
+	NatsPublishErrorConnectionClosed = "connection_closed"
+	NatsPublishErrorMaxPayload       = "max_payload"
+	NatsPublishErrorCanceled         = "canceled"
+	NatsPublishErrorOther            = "other"
 )
 
+// NatsMessageSizeBuckets range from 64B to 16MB, covering payloads up to and beyond
+// the default 1MB max_payload of the NATS server.
+var NatsMessageSizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+
 var natsMetricsCollector AsyncMessageBrokerMetricsCollector
 
 type NatsMetricsCollector struct {
+	serviceName string
+
 	processedMessageCountMetric     *prometheus.CounterVec
 	messageProcessingDurationMetric *prometheus.HistogramVec
 
 	publishedMessageCountMetric     *prometheus.CounterVec
 	messagePublishingDurationMetric *prometheus.HistogramVec
+	publishErrorCountMetric         *prometheus.CounterVec
+
+	pubAckCountMetric    *prometheus.CounterVec
+	pubAckSequenceMetric *prometheus.GaugeVec
+
+	messageEndToEndLatencyMetric *prometheus.HistogramVec
+
+	processedMessageSizeMetric *prometheus.HistogramVec
+	processedBytesCountMetric  *prometheus.CounterVec
+	publishedMessageSizeMetric *prometheus.HistogramVec
+	publishedBytesCountMetric  *prometheus.CounterVec
 }
 
-func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string) AsyncMessageBrokerMetricsCollector {
+// NewNatsMetricsCollector creates the NATS message metrics. The processed and published message
+// families are additionally labeled with the given header labels.
+func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string, headerLabels []string) AsyncMessageBrokerMetricsCollector {
+	messageLabels := append([]string{NatsSubjectLabel, NatsTypeLabel}, headerLabels...)
+
 	processedMessageCountMetric := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsProcessedMessagesTotal),
 			Help: NatsMessagesTotalHelp,
 		},
-		[]string{NatsSubjectLabel, NatsTypeLabel},
+		messageLabels,
 	)
 
 	messageProcessingDurationMetric := prometheus.NewHistogramVec(
@@ -57,7 +120,7 @@ func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string) Async
 			Help:    NatsMessageProcessingDurationHelp,
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{NatsSubjectLabel, NatsTypeLabel},
+		messageLabels,
 	)
 
 	publishedMessageCountMetric := prometheus.NewCounterVec(
@@ -65,7 +128,7 @@ func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string) Async
 			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsPublishedMessagesTotal),
 			Help: NatsPublishedMessagesHelp,
 		},
-		[]string{NatsSubjectLabel},
+		messageLabels,
 	)
 
 	messagePublishingDurationMetric := prometheus.NewHistogramVec(
@@ -74,7 +137,74 @@ func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string) Async
 			Help:    NatsPublishingMessageDurationHelp,
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{NatsSubjectLabel},
+		messageLabels,
+	)
+
+	publishErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsPublishErrorsTotal),
+			Help: NatsPublishErrorsTotalHelp,
+		},
+		[]string{NatsSubjectLabel, NatsTypeLabel, NatsReasonLabel},
+	)
+
+	pubAckCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamPubAcksTotal),
+			Help: NatsJetStreamPubAcksTotalHelp,
+		},
+		[]string{NatsStreamLabel, NatsDuplicateLabel},
+	)
+
+	pubAckSequenceMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamPubAckSequence),
+			Help: NatsJetStreamPubAckSequenceHelp,
+		},
+		[]string{NatsStreamLabel},
+	)
+
+	messageEndToEndLatencyMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMessageEndToEndLatency),
+			Help:    NatsMessageEndToEndLatencyHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsSubjectLabel, NatsSourceServiceLabel},
+	)
+
+	processedMessageSizeMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsProcessedMessageSizeBytes),
+			Help:    NatsProcessedMessageSizeBytesHelp,
+			Buckets: NatsMessageSizeBuckets,
+		},
+		messageLabels,
+	)
+
+	processedBytesCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsProcessedBytesTotal),
+			Help: NatsProcessedBytesTotalHelp,
+		},
+		messageLabels,
+	)
+
+	publishedMessageSizeMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsPublishedMessageSizeBytes),
+			Help:    NatsPublishedMessageSizeBytesHelp,
+			Buckets: NatsMessageSizeBuckets,
+		},
+		messageLabels,
+	)
+
+	publishedBytesCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsPublishedBytesTotal),
+			Help: NatsPublishedBytesTotalHelp,
+		},
+		messageLabels,
 	)
 
 	reg.MustRegister(
@@ -82,13 +212,30 @@ func NewNatsMetricsCollector(reg *prometheus.Registry, serviceName string) Async
 		publishedMessageCountMetric,
 		messageProcessingDurationMetric,
 		messagePublishingDurationMetric,
+		publishErrorCountMetric,
+		pubAckCountMetric,
+		pubAckSequenceMetric,
+		messageEndToEndLatencyMetric,
+		processedMessageSizeMetric,
+		processedBytesCountMetric,
+		publishedMessageSizeMetric,
+		publishedBytesCountMetric,
 	)
 
 	natsMetricsCollector = &NatsMetricsCollector{
+		serviceName:                     serviceName,
 		processedMessageCountMetric:     processedMessageCountMetric,
 		messageProcessingDurationMetric: messageProcessingDurationMetric,
 		publishedMessageCountMetric:     publishedMessageCountMetric,
 		messagePublishingDurationMetric: messagePublishingDurationMetric,
+		publishErrorCountMetric:         publishErrorCountMetric,
+		pubAckCountMetric:               pubAckCountMetric,
+		pubAckSequenceMetric:            pubAckSequenceMetric,
+		messageEndToEndLatencyMetric:    messageEndToEndLatencyMetric,
+		processedMessageSizeMetric:      processedMessageSizeMetric,
+		processedBytesCountMetric:       processedBytesCountMetric,
+		publishedMessageSizeMetric:      publishedMessageSizeMetric,
+		publishedBytesCountMetric:       publishedBytesCountMetric,
 	}
 
 	return natsMetricsCollector
@@ -101,18 +248,59 @@ func GetNatsMetricsCollector() (AsyncMessageBrokerMetricsCollector, error) {
 	return natsMetricsCollector, nil
 }
 
-func (m *NatsMetricsCollector) IncProcessedMessageCount(subject, messageType string) {
-	m.processedMessageCountMetric.WithLabelValues(subject, messageType).Inc()
+func (m *NatsMetricsCollector) IncProcessedMessageCount(subject, messageType string, headerLabelValues ...string) {
+	m.processedMessageCountMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Inc()
+}
+
+func (m *NatsMetricsCollector) ObserveMessageProcessingDuration(subject, messageType string, duration float64, headerLabelValues ...string) {
+	m.messageProcessingDurationMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Observe(duration)
+}
+
+func (m *NatsMetricsCollector) IncPublishedMessageCount(subject, messageType string, headerLabelValues ...string) {
+	m.publishedMessageCountMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Inc()
+}
+
+func (m *NatsMetricsCollector) ObserveMessagePublishingDuration(subject, messageType string, duration float64, headerLabelValues ...string) {
+	m.messagePublishingDurationMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Observe(duration)
+}
+
+func (m *NatsMetricsCollector) IncPublishErrorCount(subject, messageType, reason string) {
+	m.publishErrorCountMetric.WithLabelValues(subject, messageType, reason).Inc()
+}
+
+func (m *NatsMetricsCollector) IncPubAckCount(stream, duplicate string) {
+	m.pubAckCountMetric.WithLabelValues(stream, duplicate).Inc()
+}
+
+func (m *NatsMetricsCollector) SetPubAckSequence(stream string, sequence float64) {
+	m.pubAckSequenceMetric.WithLabelValues(stream).Set(sequence)
+}
+
+func (m *NatsMetricsCollector) ObserveMessageEndToEndLatency(subject, sourceService string, latency float64) {
+	m.messageEndToEndLatencyMetric.WithLabelValues(subject, sourceService).Observe(latency)
+}
+
+func (m *NatsMetricsCollector) ObserveProcessedMessageSize(subject, messageType string, size float64, headerLabelValues ...string) {
+	m.processedMessageSizeMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Observe(size)
+}
+
+func (m *NatsMetricsCollector) AddProcessedMessageBytes(subject, messageType string, size float64, headerLabelValues ...string) {
+	m.processedBytesCountMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Add(size)
+}
+
+func (m *NatsMetricsCollector) ObservePublishedMessageSize(subject, messageType string, size float64, headerLabelValues ...string) {
+	m.publishedMessageSizeMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Observe(size)
 }
 
-func (m *NatsMetricsCollector) ObserveMessageProcessingDuration(subject, messageType string, duration float64) {
-	m.messageProcessingDurationMetric.WithLabelValues(subject, messageType).Observe(duration)
+func (m *NatsMetricsCollector) AddPublishedMessageBytes(subject, messageType string, size float64, headerLabelValues ...string) {
+	m.publishedBytesCountMetric.WithLabelValues(messageLabelValues(subject, messageType, headerLabelValues)...).Add(size)
 }
 
-func (m *NatsMetricsCollector) IncPublishedMessageCount(subject, messageType string) {
-	m.publishedMessageCountMetric.WithLabelValues(subject, messageType).Inc()
+func (m *NatsMetricsCollector) GetServiceName() string {
+	return m.serviceName
 }
 
-func (m *NatsMetricsCollector) ObserveMessagePublishingDuration(subject, messageType string, duration float64) {
-	m.messagePublishingDurationMetric.WithLabelValues(subject, messageType).Observe(duration)
+// messageLabelValues returns the label values of the processed and published message families.
+func messageLabelValues(subject, messageType string, headerLabelValues []string) []string {
+	return append([]string{subject, messageType}, headerLabelValues...)
 }
@@ -0,0 +1,144 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"maps"
+	"sync"
+	"time"
+)
+
+type ActivityMetricsCollector interface {
+	SetLastProcessedMessageTime(subject string, timestamp time.Time)
+	SetLastPublishedMessageTime(subject string, timestamp time.Time)
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsLastProcessedMessageTimestamp     = "last_processed_message_timestamp_seconds"
+	NatsLastProcessedMessageTimestampHelp = "Unix timestamp of the last NATS message processed by the Fiber app."
+	NatsLastPublishedMessageTimestamp     = "last_published_message_timestamp_seconds"
+	NatsLastPublishedMessageTimestampHelp = "Unix timestamp of the last NATS message published by the Fiber app."
+	NatsSubjectIdle                       = "subject_idle"
+	NatsSubjectIdleHelp                   = "Whether no NATS message has been processed or published on the subject within the idle threshold."
+)
+
+const (
+	NatsDirectionLabel = "direction"
+
+	NatsDirectionProcessed = "processed"
+	NatsDirectionPublished = "published"
+)
+
+var natsActivityMetricsCollector ActivityMetricsCollector
+
+type NatsActivityCollector struct {
+	idleThreshold     time.Duration
+	expectedProcessed []string
+	expectedPublished []string
+
+	mu            sync.Mutex
+	lastProcessed map[string]time.Time
+	lastPublished map[string]time.Time
+
+	lastProcessedDesc *prometheus.Desc
+	lastPublishedDesc *prometheus.Desc
+	idleDesc          *prometheus.Desc
+}
+
+// NewNatsActivityCollector creates a collector of the time of the last processed and published
+// message per subject. The idle gauge is only exported when idleThreshold is positive. Expected
+// subjects are reported as idle until their first message is processed or published, so that a
+// subject that never receives a message after a restart is idle as well.
+func NewNatsActivityCollector(reg *prometheus.Registry, serviceName string, idleThreshold time.Duration, expectedProcessed, expectedPublished []string) ActivityMetricsCollector {
+	collector := &NatsActivityCollector{
+		idleThreshold:     idleThreshold,
+		expectedProcessed: expectedProcessed,
+		expectedPublished: expectedPublished,
+		lastProcessed:     make(map[string]time.Time),
+		lastPublished:     make(map[string]time.Time),
+		lastProcessedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsLastProcessedMessageTimestamp),
+			NatsLastProcessedMessageTimestampHelp,
+			[]string{NatsSubjectLabel}, nil,
+		),
+		lastPublishedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsLastPublishedMessageTimestamp),
+			NatsLastPublishedMessageTimestampHelp,
+			[]string{NatsSubjectLabel}, nil,
+		),
+		idleDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubjectIdle),
+			NatsSubjectIdleHelp,
+			[]string{NatsSubjectLabel, NatsDirectionLabel}, nil,
+		),
+	}
+
+	reg.MustRegister(collector)
+
+	natsActivityMetricsCollector = collector
+
+	return natsActivityMetricsCollector
+}
+
+func GetNatsActivityMetricsCollector() (ActivityMetricsCollector, error) {
+	if natsActivityMetricsCollector == nil {
+		return nil, errors.New("natsActivityMetricsCollector is nil")
+	}
+	return natsActivityMetricsCollector, nil
+}
+
+func (c *NatsActivityCollector) SetLastProcessedMessageTime(subject string, timestamp time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastProcessed[subject] = timestamp
+}
+
+func (c *NatsActivityCollector) SetLastPublishedMessageTime(subject string, timestamp time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastPublished[subject] = timestamp
+}
+
+func (c *NatsActivityCollector) Collect(ch chan<- prometheus.Metric) {
+	// Copy the timestamps, so that a slow scrape does not block message handlers
+	c.mu.Lock()
+	lastProcessed := maps.Clone(c.lastProcessed)
+	lastPublished := maps.Clone(c.lastPublished)
+	c.mu.Unlock()
+
+	now := time.Now()
+	c.collectDirection(ch, now, lastProcessed, c.expectedProcessed, c.lastProcessedDesc, NatsDirectionProcessed)
+	c.collectDirection(ch, now, lastPublished, c.expectedPublished, c.lastPublishedDesc, NatsDirectionPublished)
+}
+
+func (c *NatsActivityCollector) collectDirection(ch chan<- prometheus.Metric, now time.Time, lastMessages map[string]time.Time, expected []string, desc *prometheus.Desc, direction string) {
+	if c.idleThreshold > 0 {
+		for _, subject := range expected {
+			if _, ok := lastMessages[subject]; !ok {
+				ch <- prometheus.MustNewConstMetric(c.idleDesc, prometheus.GaugeValue, 1, subject, direction)
+			}
+		}
+	}
+
+	for subject, timestamp := range lastMessages {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(timestamp.UnixNano())/1e9, subject)
+
+		if c.idleThreshold > 0 {
+			idle := 0.0
+			if now.Sub(timestamp) > c.idleThreshold {
+				idle = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.idleDesc, prometheus.GaugeValue, idle, subject, direction)
+		}
+	}
+}
+
+func (c *NatsActivityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastProcessedDesc
+	ch <- c.lastPublishedDesc
+	ch <- c.idleDesc
+}
@@ -0,0 +1,82 @@
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"testing"
+	"time"
+)
+
+func TestNatsActivityCollectorExpectedSubjects(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector := NewNatsActivityCollector(reg, "svc", time.Minute, []string{"orders.created"}, []string{"orders.shipped"})
+
+	idle := gatherIdle(t, reg)
+	want := map[string]float64{"orders.created/processed": 1, "orders.shipped/published": 1}
+	if len(idle) != len(want) {
+		t.Fatalf("idle series = %v, want %v", idle, want)
+	}
+	for key, value := range want {
+		if idle[key] != value {
+			t.Errorf("idle %s = %v, want %v", key, idle[key], value)
+		}
+	}
+	if n := countSeries(t, reg, "svc_nats_last_processed_message_timestamp_seconds"); n != 0 {
+		t.Errorf("last processed timestamps = %d, want none before the first message", n)
+	}
+
+	collector.SetLastProcessedMessageTime("orders.created", time.Now())
+
+	idle = gatherIdle(t, reg)
+	if idle["orders.created/processed"] != 0 {
+		t.Errorf("idle orders.created/processed = %v, want 0 after a message", idle["orders.created/processed"])
+	}
+	if idle["orders.shipped/published"] != 1 {
+		t.Errorf("idle orders.shipped/published = %v, want 1", idle["orders.shipped/published"])
+	}
+	if n := countSeries(t, reg, "svc_nats_last_processed_message_timestamp_seconds"); n != 1 {
+		t.Errorf("last processed timestamps = %d, want 1", n)
+	}
+}
+
+// gatherIdle returns the idle gauges keyed by subject and direction.
+func gatherIdle(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	idle := make(map[string]float64)
+	for _, mf := range mfs {
+		if mf.GetName() != "svc_nats_subject_idle" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			idle[labels[NatsSubjectLabel]+"/"+labels[NatsDirectionLabel]] = m.GetGauge().GetValue()
+		}
+	}
+
+	return idle
+}
+
+func countSeries(t *testing.T, reg *prometheus.Registry, name string) int {
+	t.Helper()
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	for _, mf := range mfs {
+		if mf.GetName() == name {
+			return len(mf.GetMetric())
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,148 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type AsyncPublishMetricsCollector interface {
+	ObserveAsyncPublishAckDuration(stream, outcome string, duration float64)
+	IncAsyncPublishAckCount(stream string)
+	IncAsyncPublishDuplicateCount(stream string)
+	IncAsyncPublishErrorCount(subject, reason string)
+	SetAsyncPublishPending(pending float64)
+	ObserveAsyncPublishCallDuration(duration float64)
+}
+
+const (
+	NatsAsyncPublishAckDuration         = "jetstream_async_publish_ack_duration_seconds"
+	NatsAsyncPublishAckDurationHelp     = "Duration between an asynchronous JetStream publish and its acknowledgement or error."
+	NatsAsyncPublishAcksTotal           = "jetstream_async_publish_acks_total"
+	NatsAsyncPublishAcksTotalHelp       = "Total number of acknowledged asynchronous JetStream publishes."
+	NatsAsyncPublishDuplicatesTotal     = "jetstream_async_publish_duplicates_total"
+	NatsAsyncPublishDuplicatesTotalHelp = "Total number of asynchronous JetStream publishes acknowledged as duplicates."
+	NatsAsyncPublishErrorsTotal         = "jetstream_async_publish_errors_total"
+	NatsAsyncPublishErrorsTotalHelp     = "Total number of failed asynchronous JetStream publishes."
+	NatsAsyncPublishPending             = "jetstream_async_publish_pending"
+	NatsAsyncPublishPendingHelp         = "Number of asynchronous JetStream publishes awaiting acknowledgement."
+	NatsAsyncPublishCallDuration        = "jetstream_async_publish_call_duration_seconds"
+	NatsAsyncPublishCallDurationHelp    = "Duration of asynchronous JetStream publish calls, which block while the max pending limit is reached."
+
+	NatsAsyncPublishOutcomeAck   = "ack"
+	NatsAsyncPublishOutcomeError = "error"
+
+	// NatsUnknownStream labels asynchronous publishes that failed before a stream acknowledged them.
+	NatsUnknownStream = "unknown"
+)
+
+var natsAsyncPublishMetricsCollector AsyncPublishMetricsCollector
+
+type NatsAsyncPublishMetricsCollector struct {
+	ackDurationMetric    *prometheus.HistogramVec
+	ackCountMetric       *prometheus.CounterVec
+	duplicateCountMetric *prometheus.CounterVec
+	errorCountMetric     *prometheus.CounterVec
+	pendingGauge         prometheus.Gauge
+	callDurationMetric   prometheus.Histogram
+}
+
+func NewNatsAsyncPublishMetricsCollector(reg *prometheus.Registry, serviceName string) AsyncPublishMetricsCollector {
+	ackDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishAckDuration),
+			Help:    NatsAsyncPublishAckDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsStreamLabel, NatsOutcomeLabel},
+	)
+
+	ackCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishAcksTotal),
+			Help: NatsAsyncPublishAcksTotalHelp,
+		},
+		[]string{NatsStreamLabel},
+	)
+
+	duplicateCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishDuplicatesTotal),
+			Help: NatsAsyncPublishDuplicatesTotalHelp,
+		},
+		[]string{NatsStreamLabel},
+	)
+
+	errorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishErrorsTotal),
+			Help: NatsAsyncPublishErrorsTotalHelp,
+		},
+		[]string{NatsSubjectLabel, NatsReasonLabel},
+	)
+
+	pendingGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishPending),
+			Help: NatsAsyncPublishPendingHelp,
+		},
+	)
+
+	callDurationMetric := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsAsyncPublishCallDuration),
+			Help:    NatsAsyncPublishCallDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	reg.MustRegister(
+		ackDurationMetric,
+		ackCountMetric,
+		duplicateCountMetric,
+		errorCountMetric,
+		pendingGauge,
+		callDurationMetric,
+	)
+
+	natsAsyncPublishMetricsCollector = &NatsAsyncPublishMetricsCollector{
+		ackDurationMetric:    ackDurationMetric,
+		ackCountMetric:       ackCountMetric,
+		duplicateCountMetric: duplicateCountMetric,
+		errorCountMetric:     errorCountMetric,
+		pendingGauge:         pendingGauge,
+		callDurationMetric:   callDurationMetric,
+	}
+
+	return natsAsyncPublishMetricsCollector
+}
+
+func GetNatsAsyncPublishMetricsCollector() (AsyncPublishMetricsCollector, error) {
+	if natsAsyncPublishMetricsCollector == nil {
+		return nil, errors.New("natsAsyncPublishMetricsCollector is nil")
+	}
+	return natsAsyncPublishMetricsCollector, nil
+}
+
+func (m *NatsAsyncPublishMetricsCollector) ObserveAsyncPublishAckDuration(stream, outcome string, duration float64) {
+	m.ackDurationMetric.WithLabelValues(stream, outcome).Observe(duration)
+}
+
+func (m *NatsAsyncPublishMetricsCollector) IncAsyncPublishAckCount(stream string) {
+	m.ackCountMetric.WithLabelValues(stream).Inc()
+}
+
+func (m *NatsAsyncPublishMetricsCollector) IncAsyncPublishDuplicateCount(stream string) {
+	m.duplicateCountMetric.WithLabelValues(stream).Inc()
+}
+
+func (m *NatsAsyncPublishMetricsCollector) IncAsyncPublishErrorCount(subject, reason string) {
+	m.errorCountMetric.WithLabelValues(subject, reason).Inc()
+}
+
+func (m *NatsAsyncPublishMetricsCollector) SetAsyncPublishPending(pending float64) {
+	m.pendingGauge.Set(pending)
+}
+
+func (m *NatsAsyncPublishMetricsCollector) ObserveAsyncPublishCallDuration(duration float64) {
+	m.callDurationMetric.Observe(duration)
+}
@@ -0,0 +1,96 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BatchPublishMetricsCollector interface {
+	ObserveBatchPublishSize(size float64)
+	ObserveBatchPublishDuration(outcome string, duration float64)
+	IncBatchPublishFailedMessageCount(reason string)
+}
+
+const (
+	NatsBatchPublishSize                = "jetstream_batch_publish_size"
+	NatsBatchPublishSizeHelp            = "Number of messages of batches published to JetStream."
+	NatsBatchPublishDuration            = "jetstream_batch_publish_duration_seconds"
+	NatsBatchPublishDurationHelp        = "Duration between publishing a batch to JetStream and the acknowledgement or failure of all of its messages."
+	NatsBatchPublishFailedMessagesTotal = "jetstream_batch_publish_failed_messages_total"
+	NatsBatchPublishFailedMessagesHelp  = "Total number of messages of batches published to JetStream that failed or were not acknowledged in time."
+
+	NatsBatchPublishOutcomeComplete = "complete"
+	NatsBatchPublishOutcomePartial  = "partial"
+	NatsBatchPublishOutcomeFailed   = "failed"
+)
+
+// NatsBatchSizeBuckets range from single messages to batches of 8192 messages.
+var NatsBatchSizeBuckets = prometheus.ExponentialBuckets(1, 2, 14)
+
+var natsBatchPublishMetricsCollector BatchPublishMetricsCollector
+
+type NatsBatchPublishMetricsCollector struct {
+	sizeMetric               prometheus.Histogram
+	durationMetric           *prometheus.HistogramVec
+	failedMessageCountMetric *prometheus.CounterVec
+}
+
+func NewNatsBatchPublishMetricsCollector(reg *prometheus.Registry, serviceName string) BatchPublishMetricsCollector {
+	sizeMetric := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsBatchPublishSize),
+			Help:    NatsBatchPublishSizeHelp,
+			Buckets: NatsBatchSizeBuckets,
+		},
+	)
+
+	durationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsBatchPublishDuration),
+			Help:    NatsBatchPublishDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsOutcomeLabel},
+	)
+
+	failedMessageCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsBatchPublishFailedMessagesTotal),
+			Help: NatsBatchPublishFailedMessagesHelp,
+		},
+		[]string{NatsReasonLabel},
+	)
+
+	reg.MustRegister(
+		sizeMetric,
+		durationMetric,
+		failedMessageCountMetric,
+	)
+
+	natsBatchPublishMetricsCollector = &NatsBatchPublishMetricsCollector{
+		sizeMetric:               sizeMetric,
+		durationMetric:           durationMetric,
+		failedMessageCountMetric: failedMessageCountMetric,
+	}
+
+	return natsBatchPublishMetricsCollector
+}
+
+func GetNatsBatchPublishMetricsCollector() (BatchPublishMetricsCollector, error) {
+	if natsBatchPublishMetricsCollector == nil {
+		return nil, errors.New("natsBatchPublishMetricsCollector is nil")
+	}
+	return natsBatchPublishMetricsCollector, nil
+}
+
+func (m *NatsBatchPublishMetricsCollector) ObserveBatchPublishSize(size float64) {
+	m.sizeMetric.Observe(size)
+}
+
+func (m *NatsBatchPublishMetricsCollector) ObserveBatchPublishDuration(outcome string, duration float64) {
+	m.durationMetric.WithLabelValues(outcome).Observe(duration)
+}
+
+func (m *NatsBatchPublishMetricsCollector) IncBatchPublishFailedMessageCount(reason string) {
+	m.failedMessageCountMetric.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,107 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type CircuitBreakerMetricsCollector interface {
+	SetCircuitBreakerState(breaker, state string)
+	IncCircuitBreakerTransitionCount(breaker, from, to string)
+	IncCircuitBreakerRejectedCount(breaker string)
+}
+
+const (
+	NatsCircuitBreakerState             = "circuit_breaker_state"
+	NatsCircuitBreakerStateHelp         = "Whether the circuit breaker of NATS publishers is in the state."
+	NatsCircuitBreakerTransitions       = "circuit_breaker_transitions_total"
+	NatsCircuitBreakerTransitionsHelp   = "Total number of state transitions of the circuit breaker of NATS publishers."
+	NatsCircuitBreakerRejectedTotal     = "circuit_breaker_rejected_total"
+	NatsCircuitBreakerRejectedTotalHelp = "Total number of NATS publishes rejected by the open circuit breaker."
+
+	NatsBreakerLabel = "breaker"
+	NatsStateLabel   = "state"
+	NatsFromLabel    = "from"
+	NatsToLabel      = "to"
+
+	NatsCircuitBreakerClosed   = "closed"
+	NatsCircuitBreakerOpen     = "open"
+	NatsCircuitBreakerHalfOpen = "half_open"
+)
+
+var natsCircuitBreakerStates = []string{NatsCircuitBreakerClosed, NatsCircuitBreakerOpen, NatsCircuitBreakerHalfOpen}
+
+var natsCircuitBreakerMetricsCollector CircuitBreakerMetricsCollector
+
+type NatsCircuitBreakerMetricsCollector struct {
+	stateMetric           *prometheus.GaugeVec
+	transitionCountMetric *prometheus.CounterVec
+	rejectedCountMetric   *prometheus.CounterVec
+}
+
+func NewNatsCircuitBreakerMetricsCollector(reg *prometheus.Registry, serviceName string) CircuitBreakerMetricsCollector {
+	stateMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCircuitBreakerState),
+			Help: NatsCircuitBreakerStateHelp,
+		},
+		[]string{NatsBreakerLabel, NatsStateLabel},
+	)
+
+	transitionCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCircuitBreakerTransitions),
+			Help: NatsCircuitBreakerTransitionsHelp,
+		},
+		[]string{NatsBreakerLabel, NatsFromLabel, NatsToLabel},
+	)
+
+	rejectedCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCircuitBreakerRejectedTotal),
+			Help: NatsCircuitBreakerRejectedTotalHelp,
+		},
+		[]string{NatsBreakerLabel},
+	)
+
+	reg.MustRegister(
+		stateMetric,
+		transitionCountMetric,
+		rejectedCountMetric,
+	)
+
+	natsCircuitBreakerMetricsCollector = &NatsCircuitBreakerMetricsCollector{
+		stateMetric:           stateMetric,
+		transitionCountMetric: transitionCountMetric,
+		rejectedCountMetric:   rejectedCountMetric,
+	}
+
+	return natsCircuitBreakerMetricsCollector
+}
+
+func GetNatsCircuitBreakerMetricsCollector() (CircuitBreakerMetricsCollector, error) {
+	if natsCircuitBreakerMetricsCollector == nil {
+		return nil, errors.New("natsCircuitBreakerMetricsCollector is nil")
+	}
+	return natsCircuitBreakerMetricsCollector, nil
+}
+
+// SetCircuitBreakerState sets the gauge of the current state of the breaker to 1 and the gauges
+// of its other states to 0.
+func (m *NatsCircuitBreakerMetricsCollector) SetCircuitBreakerState(breaker, state string) {
+	for _, s := range natsCircuitBreakerStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		m.stateMetric.WithLabelValues(breaker, s).Set(value)
+	}
+}
+
+func (m *NatsCircuitBreakerMetricsCollector) IncCircuitBreakerTransitionCount(breaker, from, to string) {
+	m.transitionCountMetric.WithLabelValues(breaker, from, to).Inc()
+}
+
+func (m *NatsCircuitBreakerMetricsCollector) IncCircuitBreakerRejectedCount(breaker string) {
+	m.rejectedCountMetric.WithLabelValues(breaker).Inc()
+}
@@ -0,0 +1,116 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type CodecMetricsCollector interface {
+	ObserveDecodeDuration(subject, codec string, duration float64)
+	IncDecodeErrorCount(subject, codec string)
+	ObserveEncodeDuration(subject, codec string, duration float64)
+	IncEncodeErrorCount(subject, codec string)
+}
+
+const (
+	NatsCodecDecodeDuration        = "codec_decode_duration_seconds"
+	NatsCodecDecodeDurationHelp    = "Duration of decoding NATS message payloads into typed values."
+	NatsCodecDecodeErrorsTotal     = "codec_decode_errors_total"
+	NatsCodecDecodeErrorsTotalHelp = "Total number of NATS message payloads that failed to be decoded."
+	NatsCodecEncodeDuration        = "codec_encode_duration_seconds"
+	NatsCodecEncodeDurationHelp    = "Duration of encoding typed values into NATS message payloads."
+	NatsCodecEncodeErrorsTotal     = "codec_encode_errors_total"
+	NatsCodecEncodeErrorsTotalHelp = "Total number of typed values that failed to be encoded into NATS message payloads."
+
+	NatsCodecLabel = "codec"
+)
+
+// NatsCodecDurationBuckets range from 1µs to 262ms, since payloads are usually decoded in
+// microseconds.
+var NatsCodecDurationBuckets = prometheus.ExponentialBuckets(1e-6, 4, 10)
+
+var natsCodecMetricsCollector CodecMetricsCollector
+
+type NatsCodecMetricsCollector struct {
+	decodeDurationMetric   *prometheus.HistogramVec
+	decodeErrorCountMetric *prometheus.CounterVec
+	encodeDurationMetric   *prometheus.HistogramVec
+	encodeErrorCountMetric *prometheus.CounterVec
+}
+
+func NewNatsCodecMetricsCollector(reg *prometheus.Registry, serviceName string) CodecMetricsCollector {
+	codecLabels := []string{NatsSubjectLabel, NatsCodecLabel}
+
+	decodeDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCodecDecodeDuration),
+			Help:    NatsCodecDecodeDurationHelp,
+			Buckets: NatsCodecDurationBuckets,
+		},
+		codecLabels,
+	)
+
+	decodeErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCodecDecodeErrorsTotal),
+			Help: NatsCodecDecodeErrorsTotalHelp,
+		},
+		codecLabels,
+	)
+
+	encodeDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCodecEncodeDuration),
+			Help:    NatsCodecEncodeDurationHelp,
+			Buckets: NatsCodecDurationBuckets,
+		},
+		codecLabels,
+	)
+
+	encodeErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsCodecEncodeErrorsTotal),
+			Help: NatsCodecEncodeErrorsTotalHelp,
+		},
+		codecLabels,
+	)
+
+	reg.MustRegister(
+		decodeDurationMetric,
+		decodeErrorCountMetric,
+		encodeDurationMetric,
+		encodeErrorCountMetric,
+	)
+
+	natsCodecMetricsCollector = &NatsCodecMetricsCollector{
+		decodeDurationMetric:   decodeDurationMetric,
+		decodeErrorCountMetric: decodeErrorCountMetric,
+		encodeDurationMetric:   encodeDurationMetric,
+		encodeErrorCountMetric: encodeErrorCountMetric,
+	}
+
+	return natsCodecMetricsCollector
+}
+
+func GetNatsCodecMetricsCollector() (CodecMetricsCollector, error) {
+	if natsCodecMetricsCollector == nil {
+		return nil, errors.New("natsCodecMetricsCollector is nil")
+	}
+	return natsCodecMetricsCollector, nil
+}
+
+func (m *NatsCodecMetricsCollector) ObserveDecodeDuration(subject, codec string, duration float64) {
+	m.decodeDurationMetric.WithLabelValues(subject, codec).Observe(duration)
+}
+
+func (m *NatsCodecMetricsCollector) IncDecodeErrorCount(subject, codec string) {
+	m.decodeErrorCountMetric.WithLabelValues(subject, codec).Inc()
+}
+
+func (m *NatsCodecMetricsCollector) ObserveEncodeDuration(subject, codec string, duration float64) {
+	m.encodeDurationMetric.WithLabelValues(subject, codec).Observe(duration)
+}
+
+func (m *NatsCodecMetricsCollector) IncEncodeErrorCount(subject, codec string) {
+	m.encodeErrorCountMetric.WithLabelValues(subject, codec).Inc()
+}
@@ -0,0 +1,298 @@
+package collectors
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NatsConnectionMetricsCollector interface {
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsConnectionInMessagesTotal      = "connection_in_messages_total"
+	NatsConnectionInMessagesTotalHelp  = "Total number of messages received by the NATS connection."
+	NatsConnectionOutMessagesTotal     = "connection_out_messages_total"
+	NatsConnectionOutMessagesTotalHelp = "Total number of messages sent by the NATS connection."
+	NatsConnectionInBytesTotal         = "connection_in_bytes_total"
+	NatsConnectionInBytesTotalHelp     = "Total number of bytes received by the NATS connection."
+	NatsConnectionOutBytesTotal        = "connection_out_bytes_total"
+	NatsConnectionOutBytesTotalHelp    = "Total number of bytes sent by the NATS connection."
+	NatsConnectionReconnectsTotal      = "connection_reconnects_total"
+	NatsConnectionReconnectsTotalHelp  = "Total number of reconnects of the NATS connection."
+	NatsConnectionStatus               = "connection_status"
+	NatsConnectionStatusHelp           = "Current status of the NATS connection, 1 for the active status."
+	NatsConnectionBufferedBytes        = "connection_buffered_bytes"
+	NatsConnectionBufferedBytesHelp    = "Number of bytes buffered by the NATS connection and pending to be flushed."
+	NatsConnectionInfo                 = "connection_info"
+	NatsConnectionInfoHelp             = "Information about the NATS server the connection is connected to."
+	NatsConnectionRTT                  = "connection_rtt_seconds"
+	NatsConnectionRTTHelp              = "Round-trip time between the NATS connection and the server."
+	NatsConnectionEventsTotal          = "connection_events_total"
+	NatsConnectionEventsTotalHelp      = "Total number of NATS connection events."
+
+	NatsConnectionLabel    = "connection"
+	NatsStatusLabel        = "status"
+	NatsEventLabel         = "event"
+	NatsServerIdLabel      = "server_id"
+	NatsServerNameLabel    = "server_name"
+	NatsServerVersionLabel = "server_version"
+	NatsClusterLabel       = "cluster"
+	NatsUrlLabel           = "url"
+
+	NatsDisconnectEvent = "disconnect"
+	NatsReconnectEvent  = "reconnect"
+	NatsClosedEvent     = "closed"
+	NatsAsyncErrorEvent = "async_error"
+
+	// NatsDefaultRTTInterval is used when no RTT measurement interval is configured.
+	NatsDefaultRTTInterval = 30 * time.Second
+)
+
+var natsConnectionStatuses = []nats.Status{
+	nats.DISCONNECTED,
+	nats.CONNECTED,
+	nats.CLOSED,
+	nats.RECONNECTING,
+	nats.CONNECTING,
+	nats.DRAINING_SUBS,
+	nats.DRAINING_PUBS,
+}
+
+type natsConnection struct {
+	conn *nats.Conn
+	name string
+}
+
+type NatsConnectionCollector struct {
+	connections []natsConnection
+
+	inMessagesDesc  *prometheus.Desc
+	outMessagesDesc *prometheus.Desc
+	inBytesDesc     *prometheus.Desc
+	outBytesDesc    *prometheus.Desc
+	reconnectsDesc  *prometheus.Desc
+	statusDesc      *prometheus.Desc
+	bufferedDesc    *prometheus.Desc
+	infoDesc        *prometheus.Desc
+
+	rttMetric        *prometheus.HistogramVec
+	eventCountMetric *prometheus.CounterVec
+}
+
+// NewNatsConnectionCollector exports the health of the given NATS connections. Connections are
+// labeled by their configured name, or by their position when unnamed. The round-trip time of
+// each connection is measured every rttInterval until the connection is closed.
+func NewNatsConnectionCollector(reg *prometheus.Registry, serviceName string, conns []*nats.Conn, rttInterval time.Duration) NatsConnectionMetricsCollector {
+	connectionLabels := []string{NatsConnectionLabel}
+
+	collector := &NatsConnectionCollector{
+		inMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionInMessagesTotal),
+			NatsConnectionInMessagesTotalHelp,
+			connectionLabels, nil,
+		),
+		outMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionOutMessagesTotal),
+			NatsConnectionOutMessagesTotalHelp,
+			connectionLabels, nil,
+		),
+		inBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionInBytesTotal),
+			NatsConnectionInBytesTotalHelp,
+			connectionLabels, nil,
+		),
+		outBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionOutBytesTotal),
+			NatsConnectionOutBytesTotalHelp,
+			connectionLabels, nil,
+		),
+		reconnectsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionReconnectsTotal),
+			NatsConnectionReconnectsTotalHelp,
+			connectionLabels, nil,
+		),
+		statusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionStatus),
+			NatsConnectionStatusHelp,
+			[]string{NatsConnectionLabel, NatsStatusLabel}, nil,
+		),
+		bufferedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionBufferedBytes),
+			NatsConnectionBufferedBytesHelp,
+			connectionLabels, nil,
+		),
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionInfo),
+			NatsConnectionInfoHelp,
+			[]string{NatsConnectionLabel, NatsServerIdLabel, NatsServerNameLabel, NatsServerVersionLabel, NatsClusterLabel, NatsUrlLabel}, nil,
+		),
+		rttMetric: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionRTT),
+				Help:    NatsConnectionRTTHelp,
+				Buckets: prometheus.DefBuckets,
+			},
+			connectionLabels,
+		),
+		eventCountMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsConnectionEventsTotal),
+				Help: NatsConnectionEventsTotalHelp,
+			},
+			[]string{NatsConnectionLabel, NatsEventLabel},
+		),
+	}
+
+	if rttInterval <= 0 {
+		rttInterval = NatsDefaultRTTInterval
+	}
+
+	names := connectionNames(conns)
+	for i, nc := range conns {
+		connection := natsConnection{conn: nc, name: names[i]}
+		collector.connections = append(collector.connections, connection)
+		collector.hookConnectionHandlers(connection)
+		go collector.measureRTT(connection, rttInterval)
+	}
+
+	reg.MustRegister(collector, collector.rttMetric, collector.eventCountMetric)
+	return collector
+}
+
+// connectionNames labels the connections by their name, or by their index when unnamed. Names
+// shared by several connections get a #n suffix from the second connection on, since duplicate
+// label sets fail the whole scrape.
+func connectionNames(conns []*nats.Conn) []string {
+	names := make([]string, 0, len(conns))
+	seen := make(map[string]struct{}, len(conns))
+	for i, nc := range conns {
+		base := nc.Opts.Name
+		if base == "" {
+			base = strconv.Itoa(i)
+		}
+
+		name := base
+		for n := 2; ; n++ {
+			if _, ok := seen[name]; !ok {
+				break
+			}
+			name = base + "#" + strconv.Itoa(n)
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (c *NatsConnectionCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, connection := range c.connections {
+		nc := connection.conn
+
+		// Connection statistics
+		stats := nc.Stats()
+		ch <- prometheus.MustNewConstMetric(c.inMessagesDesc, prometheus.CounterValue, float64(stats.InMsgs), connection.name)
+		ch <- prometheus.MustNewConstMetric(c.outMessagesDesc, prometheus.CounterValue, float64(stats.OutMsgs), connection.name)
+		ch <- prometheus.MustNewConstMetric(c.inBytesDesc, prometheus.CounterValue, float64(stats.InBytes), connection.name)
+		ch <- prometheus.MustNewConstMetric(c.outBytesDesc, prometheus.CounterValue, float64(stats.OutBytes), connection.name)
+		ch <- prometheus.MustNewConstMetric(c.reconnectsDesc, prometheus.CounterValue, float64(stats.Reconnects), connection.name)
+
+		// Connection status as a state set
+		status := nc.Status()
+		for _, s := range natsConnectionStatuses {
+			value := 0.0
+			if s == status {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.statusDesc, prometheus.GaugeValue, value, connection.name, strings.ToLower(s.String()))
+		}
+
+		// Buffered bytes, unavailable once the connection is closed
+		if buffered, err := nc.Buffered(); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.bufferedDesc, prometheus.GaugeValue, float64(buffered), connection.name)
+		}
+
+		// Connected server info
+		if status == nats.CONNECTED {
+			ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1,
+				connection.name,
+				nc.ConnectedServerId(),
+				nc.ConnectedServerName(),
+				nc.ConnectedServerVersion(),
+				nc.ConnectedClusterName(),
+				nc.ConnectedUrlRedacted(),
+			)
+		}
+	}
+}
+
+func (c *NatsConnectionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inMessagesDesc
+	ch <- c.outMessagesDesc
+	ch <- c.inBytesDesc
+	ch <- c.outBytesDesc
+	ch <- c.reconnectsDesc
+	ch <- c.statusDesc
+	ch <- c.bufferedDesc
+	ch <- c.infoDesc
+}
+
+// hookConnectionHandlers counts connection events while preserving the handlers already
+// configured on the connection.
+func (c *NatsConnectionCollector) hookConnectionHandlers(connection natsConnection) {
+	nc := connection.conn
+
+	disconnectHandler := nc.DisconnectErrHandler()
+	nc.SetDisconnectErrHandler(func(conn *nats.Conn, err error) {
+		c.eventCountMetric.WithLabelValues(connection.name, NatsDisconnectEvent).Inc()
+		if disconnectHandler != nil {
+			disconnectHandler(conn, err)
+		}
+	})
+
+	reconnectHandler := nc.ReconnectHandler()
+	nc.SetReconnectHandler(func(conn *nats.Conn) {
+		c.eventCountMetric.WithLabelValues(connection.name, NatsReconnectEvent).Inc()
+		if reconnectHandler != nil {
+			reconnectHandler(conn)
+		}
+	})
+
+	closedHandler := nc.ClosedHandler()
+	nc.SetClosedHandler(func(conn *nats.Conn) {
+		c.eventCountMetric.WithLabelValues(connection.name, NatsClosedEvent).Inc()
+		if closedHandler != nil {
+			closedHandler(conn)
+		}
+	})
+
+	errorHandler := nc.ErrorHandler()
+	nc.SetErrorHandler(func(conn *nats.Conn, sub *nats.Subscription, err error) {
+		c.eventCountMetric.WithLabelValues(connection.name, NatsAsyncErrorEvent).Inc()
+		if errorHandler != nil {
+			errorHandler(conn, sub, err)
+		}
+	})
+}
+
+func (c *NatsConnectionCollector) measureRTT(connection natsConnection, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if connection.conn.IsClosed() {
+			return
+		}
+
+		rtt, err := connection.conn.RTT()
+		if err != nil {
+			continue
+		}
+		c.rttMetric.WithLabelValues(connection.name).Observe(rtt.Seconds())
+	}
+}
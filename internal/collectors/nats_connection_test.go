@@ -0,0 +1,45 @@
+package collectors
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"testing"
+	"time"
+)
+
+func TestNatsConnectionCollectorSharedNames(t *testing.T) {
+	conns := []*nats.Conn{
+		{Opts: nats.Options{Name: "orders"}},
+		{Opts: nats.Options{Name: "orders"}},
+		{Opts: nats.Options{Name: "orders#2"}},
+		{},
+	}
+
+	reg := prometheus.NewRegistry()
+	NewNatsConnectionCollector(reg, "svc", conns, time.Hour)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	var names []string
+	for _, mf := range mfs {
+		if mf.GetName() != "svc_nats_connection_in_messages_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			names = append(names, m.GetLabel()[0].GetValue())
+		}
+	}
+
+	want := map[string]bool{"orders": true, "orders#2": true, "orders#2#2": true, "3": true}
+	if len(names) != len(want) {
+		t.Fatalf("connection labels = %v, want %d distinct labels", names, len(want))
+	}
+	for _, name := range names {
+		if !want[name] {
+			t.Errorf("unexpected connection label %q in %v", name, names)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type HandlerMetricsCollector interface {
+	IncHandlerErrorCount(subject, messageType string)
+	IncHandlerTimeoutCount(subject, messageType string)
+	IncHeartbeatCount(subject string)
+	IncHardLimitExceededCount(subject string)
+}
+
+const (
+	NatsHandlerErrorsTotal                = "handler_errors_total"
+	NatsHandlerErrorsTotalHelp            = "Total number of errors returned by context-aware NATS message handlers and micro service endpoint handlers."
+	NatsHandlerTimeoutsTotal              = "handler_timeouts_total"
+	NatsHandlerTimeoutsTotalHelp          = "Total number of NATS message handlers that exceeded their deadline before returning."
+	NatsHandlerHeartbeatsTotal            = "handler_heartbeats_total"
+	NatsHandlerHeartbeatsTotalHelp        = "Total number of in-progress acknowledgements sent while JetStream message handlers were running."
+	NatsHandlerHardLimitExceededTotal     = "handler_hard_limit_exceeded_total"
+	NatsHandlerHardLimitExceededTotalHelp = "Total number of JetStream message handlers that exceeded their hard limit."
+)
+
+var natsHandlerMetricsCollector HandlerMetricsCollector
+
+type NatsHandlerMetricsCollector struct {
+	handlerErrorCountMetric   *prometheus.CounterVec
+	handlerTimeoutCountMetric *prometheus.CounterVec
+	heartbeatCountMetric      *prometheus.CounterVec
+	hardLimitExceededMetric   *prometheus.CounterVec
+}
+
+func NewNatsHandlerMetricsCollector(reg *prometheus.Registry, serviceName string) HandlerMetricsCollector {
+	handlerErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsHandlerErrorsTotal),
+			Help: NatsHandlerErrorsTotalHelp,
+		},
+		[]string{NatsSubjectLabel, NatsTypeLabel},
+	)
+
+	handlerTimeoutCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsHandlerTimeoutsTotal),
+			Help: NatsHandlerTimeoutsTotalHelp,
+		},
+		[]string{NatsSubjectLabel, NatsTypeLabel},
+	)
+
+	heartbeatCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsHandlerHeartbeatsTotal),
+			Help: NatsHandlerHeartbeatsTotalHelp,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	hardLimitExceededMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsHandlerHardLimitExceededTotal),
+			Help: NatsHandlerHardLimitExceededTotalHelp,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	reg.MustRegister(
+		handlerErrorCountMetric,
+		handlerTimeoutCountMetric,
+		heartbeatCountMetric,
+		hardLimitExceededMetric,
+	)
+
+	natsHandlerMetricsCollector = &NatsHandlerMetricsCollector{
+		handlerErrorCountMetric:   handlerErrorCountMetric,
+		handlerTimeoutCountMetric: handlerTimeoutCountMetric,
+		heartbeatCountMetric:      heartbeatCountMetric,
+		hardLimitExceededMetric:   hardLimitExceededMetric,
+	}
+
+	return natsHandlerMetricsCollector
+}
+
+func GetNatsHandlerMetricsCollector() (HandlerMetricsCollector, error) {
+	if natsHandlerMetricsCollector == nil {
+		return nil, errors.New("natsHandlerMetricsCollector is nil")
+	}
+	return natsHandlerMetricsCollector, nil
+}
+
+func (m *NatsHandlerMetricsCollector) IncHandlerErrorCount(subject, messageType string) {
+	m.handlerErrorCountMetric.WithLabelValues(subject, messageType).Inc()
+}
+
+func (m *NatsHandlerMetricsCollector) IncHandlerTimeoutCount(subject, messageType string) {
+	m.handlerTimeoutCountMetric.WithLabelValues(subject, messageType).Inc()
+}
+
+func (m *NatsHandlerMetricsCollector) IncHeartbeatCount(subject string) {
+	m.heartbeatCountMetric.WithLabelValues(subject).Inc()
+}
+
+func (m *NatsHandlerMetricsCollector) IncHardLimitExceededCount(subject string) {
+	m.hardLimitExceededMetric.WithLabelValues(subject).Inc()
+}
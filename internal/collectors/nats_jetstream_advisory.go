@@ -0,0 +1,100 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type JetStreamAdvisoryMetricsCollector interface {
+	IncAdvisoryCount(stream, consumer, advisory string)
+	IncDeadLetterCount(stream, consumer string)
+	IncDeadLetterErrorCount(stream, consumer string)
+}
+
+const (
+	NatsJetStreamAdvisoriesTotal      = "jetstream_advisories_total"
+	NatsJetStreamAdvisoriesTotalHelp  = "Total number of JetStream consumer advisories received by advisory type."
+	NatsJetStreamDeadLettersTotal     = "jetstream_dead_letters_total"
+	NatsJetStreamDeadLettersTotalHelp = "Total number of message references republished to the dead-letter subject."
+	NatsJetStreamDeadLetterErrors     = "jetstream_dead_letter_errors_total"
+	NatsJetStreamDeadLetterErrorsHelp = "Total number of message references that failed to be republished to the dead-letter subject."
+
+	NatsAdvisoryLabel = "advisory"
+
+	NatsMaxDeliveriesAdvisory = "max_deliveries"
+	NatsTerminatedAdvisory    = "terminated"
+	NatsNakedAdvisory         = "naked"
+	// NatsInvalidAdvisory counts advisories whose payload could not be decoded, labeled with the
+	// unknown stream and consumer.
+	NatsInvalidAdvisory = "invalid"
+	NatsUnknownConsumer = "unknown"
+)
+
+var natsJetStreamAdvisoryMetricsCollector JetStreamAdvisoryMetricsCollector
+
+type NatsJetStreamAdvisoryMetricsCollector struct {
+	advisoryCountMetric        *prometheus.CounterVec
+	deadLetterCountMetric      *prometheus.CounterVec
+	deadLetterErrorCountMetric *prometheus.CounterVec
+}
+
+func NewNatsJetStreamAdvisoryMetricsCollector(reg *prometheus.Registry, serviceName string) JetStreamAdvisoryMetricsCollector {
+	consumerLabels := []string{NatsStreamLabel, NatsConsumerLabel}
+
+	advisoryCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamAdvisoriesTotal),
+			Help: NatsJetStreamAdvisoriesTotalHelp,
+		},
+		[]string{NatsStreamLabel, NatsConsumerLabel, NatsAdvisoryLabel},
+	)
+
+	deadLetterCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamDeadLettersTotal),
+			Help: NatsJetStreamDeadLettersTotalHelp,
+		},
+		consumerLabels,
+	)
+
+	deadLetterErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamDeadLetterErrors),
+			Help: NatsJetStreamDeadLetterErrorsHelp,
+		},
+		consumerLabels,
+	)
+
+	reg.MustRegister(
+		advisoryCountMetric,
+		deadLetterCountMetric,
+		deadLetterErrorCountMetric,
+	)
+
+	natsJetStreamAdvisoryMetricsCollector = &NatsJetStreamAdvisoryMetricsCollector{
+		advisoryCountMetric:        advisoryCountMetric,
+		deadLetterCountMetric:      deadLetterCountMetric,
+		deadLetterErrorCountMetric: deadLetterErrorCountMetric,
+	}
+
+	return natsJetStreamAdvisoryMetricsCollector
+}
+
+func GetNatsJetStreamAdvisoryMetricsCollector() (JetStreamAdvisoryMetricsCollector, error) {
+	if natsJetStreamAdvisoryMetricsCollector == nil {
+		return nil, errors.New("natsJetStreamAdvisoryMetricsCollector is nil")
+	}
+	return natsJetStreamAdvisoryMetricsCollector, nil
+}
+
+func (m *NatsJetStreamAdvisoryMetricsCollector) IncAdvisoryCount(stream, consumer, advisory string) {
+	m.advisoryCountMetric.WithLabelValues(stream, consumer, advisory).Inc()
+}
+
+func (m *NatsJetStreamAdvisoryMetricsCollector) IncDeadLetterCount(stream, consumer string) {
+	m.deadLetterCountMetric.WithLabelValues(stream, consumer).Inc()
+}
+
+func (m *NatsJetStreamAdvisoryMetricsCollector) IncDeadLetterErrorCount(stream, consumer string) {
+	m.deadLetterErrorCountMetric.WithLabelValues(stream, consumer).Inc()
+}
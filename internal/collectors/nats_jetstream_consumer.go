@@ -0,0 +1,134 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type JetStreamConsumerMetricsCollector interface {
+	ObserveFetchBatchSize(stream, consumer string, size float64)
+	ObserveFetchDuration(stream, consumer string, duration float64)
+	IncEmptyFetchCount(stream, consumer string)
+	IncFetchErrorCount(stream, consumer string)
+	ObserveNextWaitDuration(stream, consumer string, duration float64)
+}
+
+const (
+	NatsJetStreamFetchBatchSize        = "jetstream_fetch_batch_size"
+	NatsJetStreamFetchBatchSizeHelp    = "Number of messages received by JetStream fetch requests."
+	NatsJetStreamFetchDuration         = "jetstream_fetch_duration_seconds"
+	NatsJetStreamFetchDurationHelp     = "Duration of JetStream fetch requests until the batch is complete."
+	NatsJetStreamEmptyFetchesTotal     = "jetstream_empty_fetches_total"
+	NatsJetStreamEmptyFetchesTotalHelp = "Total number of JetStream fetch requests that returned no messages."
+	NatsJetStreamFetchErrorsTotal      = "jetstream_fetch_errors_total"
+	NatsJetStreamFetchErrorsTotalHelp  = "Total number of failed JetStream fetch requests."
+	NatsJetStreamNextWaitDuration      = "jetstream_next_wait_duration_seconds"
+	NatsJetStreamNextWaitDurationHelp  = "Time spent waiting for the next message of a JetStream messages iterator."
+
+	NatsConsumerLabel = "consumer"
+)
+
+// NatsFetchBatchSizeBuckets range from 1 to 1024 messages.
+var NatsFetchBatchSizeBuckets = prometheus.ExponentialBuckets(1, 2, 11)
+
+var natsJetStreamConsumerMetricsCollector JetStreamConsumerMetricsCollector
+
+type NatsJetStreamConsumerMetricsCollector struct {
+	fetchBatchSizeMetric   *prometheus.HistogramVec
+	fetchDurationMetric    *prometheus.HistogramVec
+	emptyFetchCountMetric  *prometheus.CounterVec
+	fetchErrorCountMetric  *prometheus.CounterVec
+	nextWaitDurationMetric *prometheus.HistogramVec
+}
+
+func NewNatsJetStreamConsumerMetricsCollector(reg *prometheus.Registry, serviceName string) JetStreamConsumerMetricsCollector {
+	consumerLabels := []string{NatsStreamLabel, NatsConsumerLabel}
+
+	fetchBatchSizeMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamFetchBatchSize),
+			Help:    NatsJetStreamFetchBatchSizeHelp,
+			Buckets: NatsFetchBatchSizeBuckets,
+		},
+		consumerLabels,
+	)
+
+	fetchDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamFetchDuration),
+			Help:    NatsJetStreamFetchDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		consumerLabels,
+	)
+
+	emptyFetchCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamEmptyFetchesTotal),
+			Help: NatsJetStreamEmptyFetchesTotalHelp,
+		},
+		consumerLabels,
+	)
+
+	fetchErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamFetchErrorsTotal),
+			Help: NatsJetStreamFetchErrorsTotalHelp,
+		},
+		consumerLabels,
+	)
+
+	nextWaitDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamNextWaitDuration),
+			Help:    NatsJetStreamNextWaitDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		consumerLabels,
+	)
+
+	reg.MustRegister(
+		fetchBatchSizeMetric,
+		fetchDurationMetric,
+		emptyFetchCountMetric,
+		fetchErrorCountMetric,
+		nextWaitDurationMetric,
+	)
+
+	natsJetStreamConsumerMetricsCollector = &NatsJetStreamConsumerMetricsCollector{
+		fetchBatchSizeMetric:   fetchBatchSizeMetric,
+		fetchDurationMetric:    fetchDurationMetric,
+		emptyFetchCountMetric:  emptyFetchCountMetric,
+		fetchErrorCountMetric:  fetchErrorCountMetric,
+		nextWaitDurationMetric: nextWaitDurationMetric,
+	}
+
+	return natsJetStreamConsumerMetricsCollector
+}
+
+func GetNatsJetStreamConsumerMetricsCollector() (JetStreamConsumerMetricsCollector, error) {
+	if natsJetStreamConsumerMetricsCollector == nil {
+		return nil, errors.New("natsJetStreamConsumerMetricsCollector is nil")
+	}
+	return natsJetStreamConsumerMetricsCollector, nil
+}
+
+func (m *NatsJetStreamConsumerMetricsCollector) ObserveFetchBatchSize(stream, consumer string, size float64) {
+	m.fetchBatchSizeMetric.WithLabelValues(stream, consumer).Observe(size)
+}
+
+func (m *NatsJetStreamConsumerMetricsCollector) ObserveFetchDuration(stream, consumer string, duration float64) {
+	m.fetchDurationMetric.WithLabelValues(stream, consumer).Observe(duration)
+}
+
+func (m *NatsJetStreamConsumerMetricsCollector) IncEmptyFetchCount(stream, consumer string) {
+	m.emptyFetchCountMetric.WithLabelValues(stream, consumer).Inc()
+}
+
+func (m *NatsJetStreamConsumerMetricsCollector) IncFetchErrorCount(stream, consumer string) {
+	m.fetchErrorCountMetric.WithLabelValues(stream, consumer).Inc()
+}
+
+func (m *NatsJetStreamConsumerMetricsCollector) ObserveNextWaitDuration(stream, consumer string, duration float64) {
+	m.nextWaitDurationMetric.WithLabelValues(stream, consumer).Observe(duration)
+}
@@ -0,0 +1,337 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+type JetStreamStateMetricsCollector interface {
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsJetStreamStreamMessages             = "jetstream_stream_messages"
+	NatsJetStreamStreamMessagesHelp         = "Number of messages stored in the JetStream stream."
+	NatsJetStreamStreamBytes                = "jetstream_stream_bytes"
+	NatsJetStreamStreamBytesHelp            = "Number of bytes stored in the JetStream stream."
+	NatsJetStreamStreamFirstSequence        = "jetstream_stream_first_sequence"
+	NatsJetStreamStreamFirstSequenceHelp    = "Sequence of the first message stored in the JetStream stream."
+	NatsJetStreamStreamLastSequence         = "jetstream_stream_last_sequence"
+	NatsJetStreamStreamLastSequenceHelp     = "Sequence of the last message stored in the JetStream stream."
+	NatsJetStreamStreamConsumers            = "jetstream_stream_consumers"
+	NatsJetStreamStreamConsumersHelp        = "Number of consumers of the JetStream stream."
+	NatsJetStreamConsumerNumPending         = "jetstream_consumer_num_pending"
+	NatsJetStreamConsumerNumPendingHelp     = "Number of stream messages matching the consumer that have not been delivered yet."
+	NatsJetStreamConsumerNumAckPending      = "jetstream_consumer_num_ack_pending"
+	NatsJetStreamConsumerNumAckPendingHelp  = "Number of messages delivered by the consumer and pending acknowledgement."
+	NatsJetStreamConsumerNumRedelivered     = "jetstream_consumer_num_redelivered"
+	NatsJetStreamConsumerNumRedeliveredHelp = "Number of messages redelivered by the consumer and not yet acknowledged."
+	NatsJetStreamConsumerNumWaiting         = "jetstream_consumer_num_waiting"
+	NatsJetStreamConsumerNumWaitingHelp     = "Number of pull requests waiting for messages of the consumer."
+	NatsJetStreamConsumerLastActiveAge      = "jetstream_consumer_last_active_age_seconds"
+	NatsJetStreamConsumerLastActiveAgeHelp  = "Time since the consumer last delivered a message."
+	NatsJetStreamStatePollDuration          = "jetstream_state_poll_duration_seconds"
+	NatsJetStreamStatePollDurationHelp      = "Duration of polling the JetStream stream and consumer state."
+	NatsJetStreamStatePollFailuresTotal     = "jetstream_state_poll_failures_total"
+	NatsJetStreamStatePollFailuresTotalHelp = "Total number of failed JetStream state requests by operation."
+
+	NatsStreamNamesOperation   = "stream_names"
+	NatsStreamInfoOperation    = "stream_info"
+	NatsConsumerNamesOperation = "consumer_names"
+	NatsConsumerInfoOperation  = "consumer_info"
+
+	// NatsDefaultJetStreamPollInterval is used when no JetStream state poll interval is configured.
+	NatsDefaultJetStreamPollInterval = 30 * time.Second
+	// NatsDefaultJetStreamPollTimeout is used when no JetStream state request timeout is configured.
+	NatsDefaultJetStreamPollTimeout = 5 * time.Second
+)
+
+type jetStreamState struct {
+	streams   []*nats.StreamInfo
+	consumers []*nats.ConsumerInfo
+}
+
+type NatsJetStreamStateCollector struct {
+	js      nats.JetStreamContext
+	streams map[string][]string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	state jetStreamState
+
+	streamMessagesDesc         *prometheus.Desc
+	streamBytesDesc            *prometheus.Desc
+	streamFirstSequenceDesc    *prometheus.Desc
+	streamLastSequenceDesc     *prometheus.Desc
+	streamConsumersDesc        *prometheus.Desc
+	consumerNumPendingDesc     *prometheus.Desc
+	consumerNumAckPendingDesc  *prometheus.Desc
+	consumerNumRedeliveredDesc *prometheus.Desc
+	consumerNumWaitingDesc     *prometheus.Desc
+	consumerLastActiveAgeDesc  *prometheus.Desc
+
+	pollDurationMetric     prometheus.Histogram
+	pollFailureCountMetric *prometheus.CounterVec
+}
+
+// NewNatsJetStreamStateCollector exports the state of the given streams and their consumers,
+// polled every interval with requests bounded by timeout. Streams are mapped to the names of the
+// consumers to export, where an empty list exports all consumers of the stream. When no streams
+// are given, all streams are discovered on every poll. Polling stops and the state is no longer
+// exported once the connection of the JetStream context is closed, and polling is disabled when
+// js is nil.
+func NewNatsJetStreamStateCollector(reg *prometheus.Registry, serviceName string, js nats.JetStreamContext, streams map[string][]string, interval, timeout time.Duration) JetStreamStateMetricsCollector {
+	streamLabels := []string{NatsStreamLabel}
+	consumerLabels := []string{NatsStreamLabel, NatsConsumerLabel}
+
+	if interval <= 0 {
+		interval = NatsDefaultJetStreamPollInterval
+	}
+	if timeout <= 0 {
+		timeout = NatsDefaultJetStreamPollTimeout
+	}
+
+	collector := &NatsJetStreamStateCollector{
+		js:      js,
+		streams: streams,
+		timeout: timeout,
+		streamMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStreamMessages),
+			NatsJetStreamStreamMessagesHelp,
+			streamLabels, nil,
+		),
+		streamBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStreamBytes),
+			NatsJetStreamStreamBytesHelp,
+			streamLabels, nil,
+		),
+		streamFirstSequenceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStreamFirstSequence),
+			NatsJetStreamStreamFirstSequenceHelp,
+			streamLabels, nil,
+		),
+		streamLastSequenceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStreamLastSequence),
+			NatsJetStreamStreamLastSequenceHelp,
+			streamLabels, nil,
+		),
+		streamConsumersDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStreamConsumers),
+			NatsJetStreamStreamConsumersHelp,
+			streamLabels, nil,
+		),
+		consumerNumPendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamConsumerNumPending),
+			NatsJetStreamConsumerNumPendingHelp,
+			consumerLabels, nil,
+		),
+		consumerNumAckPendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamConsumerNumAckPending),
+			NatsJetStreamConsumerNumAckPendingHelp,
+			consumerLabels, nil,
+		),
+		consumerNumRedeliveredDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamConsumerNumRedelivered),
+			NatsJetStreamConsumerNumRedeliveredHelp,
+			consumerLabels, nil,
+		),
+		consumerNumWaitingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamConsumerNumWaiting),
+			NatsJetStreamConsumerNumWaitingHelp,
+			consumerLabels, nil,
+		),
+		consumerLastActiveAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamConsumerLastActiveAge),
+			NatsJetStreamConsumerLastActiveAgeHelp,
+			consumerLabels, nil,
+		),
+		pollDurationMetric: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStatePollDuration),
+				Help:    NatsJetStreamStatePollDurationHelp,
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+		pollFailureCountMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamStatePollFailuresTotal),
+				Help: NatsJetStreamStatePollFailuresTotalHelp,
+			},
+			[]string{NatsOperationLabel},
+		),
+	}
+
+	if js != nil {
+		go collector.poll(interval)
+	}
+
+	reg.MustRegister(collector, collector.pollDurationMetric, collector.pollFailureCountMetric)
+	return collector
+}
+
+func (c *NatsJetStreamStateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	state := c.state
+	c.mu.Unlock()
+
+	for _, info := range state.streams {
+		name := info.Config.Name
+		ch <- prometheus.MustNewConstMetric(c.streamMessagesDesc, prometheus.GaugeValue, float64(info.State.Msgs), name)
+		ch <- prometheus.MustNewConstMetric(c.streamBytesDesc, prometheus.GaugeValue, float64(info.State.Bytes), name)
+		ch <- prometheus.MustNewConstMetric(c.streamFirstSequenceDesc, prometheus.GaugeValue, float64(info.State.FirstSeq), name)
+		ch <- prometheus.MustNewConstMetric(c.streamLastSequenceDesc, prometheus.GaugeValue, float64(info.State.LastSeq), name)
+		ch <- prometheus.MustNewConstMetric(c.streamConsumersDesc, prometheus.GaugeValue, float64(info.State.Consumers), name)
+	}
+
+	for _, info := range state.consumers {
+		ch <- prometheus.MustNewConstMetric(c.consumerNumPendingDesc, prometheus.GaugeValue, float64(info.NumPending), info.Stream, info.Name)
+		ch <- prometheus.MustNewConstMetric(c.consumerNumAckPendingDesc, prometheus.GaugeValue, float64(info.NumAckPending), info.Stream, info.Name)
+		ch <- prometheus.MustNewConstMetric(c.consumerNumRedeliveredDesc, prometheus.GaugeValue, float64(info.NumRedelivered), info.Stream, info.Name)
+		ch <- prometheus.MustNewConstMetric(c.consumerNumWaitingDesc, prometheus.GaugeValue, float64(info.NumWaiting), info.Stream, info.Name)
+
+		// Consumers that never delivered a message have no last activity
+		if last := info.Delivered.Last; last != nil {
+			ch <- prometheus.MustNewConstMetric(c.consumerLastActiveAgeDesc, prometheus.GaugeValue, time.Since(*last).Seconds(), info.Stream, info.Name)
+		}
+	}
+}
+
+func (c *NatsJetStreamStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.streamMessagesDesc
+	ch <- c.streamBytesDesc
+	ch <- c.streamFirstSequenceDesc
+	ch <- c.streamLastSequenceDesc
+	ch <- c.streamConsumersDesc
+	ch <- c.consumerNumPendingDesc
+	ch <- c.consumerNumAckPendingDesc
+	ch <- c.consumerNumRedeliveredDesc
+	ch <- c.consumerNumWaitingDesc
+	ch <- c.consumerLastActiveAgeDesc
+}
+
+func (c *NatsJetStreamStateCollector) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if !c.pollState() {
+			c.mu.Lock()
+			c.state = jetStreamState{}
+			c.mu.Unlock()
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// pollState replaces the exported state with the current state of the streams and consumers.
+// Streams and consumers whose info could not be fetched are left out until the next poll.
+// It returns false once the connection is closed.
+func (c *NatsJetStreamStateCollector) pollState() bool {
+	startTime := time.Now()
+	defer func() {
+		c.pollDurationMetric.Observe(time.Since(startTime).Seconds())
+	}()
+
+	streams := c.streams
+	if len(streams) == 0 {
+		streams = make(map[string][]string)
+		names, err := c.names(NatsStreamNamesOperation, c.js.StreamNames)
+		if errors.Is(err, nats.ErrConnectionClosed) {
+			return false
+		}
+		for _, name := range names {
+			streams[name] = nil
+		}
+	}
+
+	var state jetStreamState
+	for stream, consumers := range streams {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		streamInfo, err := c.js.StreamInfo(stream, nats.Context(ctx))
+		cancel()
+		if errors.Is(err, nats.ErrConnectionClosed) {
+			return false
+		}
+		if err != nil {
+			c.pollFailureCountMetric.WithLabelValues(NatsStreamInfoOperation).Inc()
+			continue
+		}
+		state.streams = append(state.streams, streamInfo)
+
+		if len(consumers) == 0 {
+			consumers, err = c.names(NatsConsumerNamesOperation, func(opts ...nats.JSOpt) <-chan string {
+				return c.js.ConsumerNames(stream, opts...)
+			})
+			if errors.Is(err, nats.ErrConnectionClosed) {
+				return false
+			}
+		}
+
+		for _, consumer := range consumers {
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			consumerInfo, err := c.js.ConsumerInfo(stream, consumer, nats.Context(ctx))
+			cancel()
+			if errors.Is(err, nats.ErrConnectionClosed) {
+				return false
+			}
+			if err != nil {
+				c.pollFailureCountMetric.WithLabelValues(NatsConsumerInfoOperation).Inc()
+				continue
+			}
+			state.consumers = append(state.consumers, consumerInfo)
+		}
+	}
+
+	c.mu.Lock()
+	c.state = state
+	c.mu.Unlock()
+
+	return true
+}
+
+// names collects the names returned by a JetStream names lister. Listers do not report errors,
+// so only listings that did not complete within the timeout are counted as failures, and empty
+// or failed listings check whether the connection is closed, returning nats.ErrConnectionClosed.
+func (c *NatsJetStreamStateCollector) names(operation string, list func(opts ...nats.JSOpt) <-chan string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var names []string
+	ch := list(nats.Context(ctx))
+	if ch != nil {
+		for name := range ch {
+			names = append(names, name)
+		}
+	}
+
+	failed := ch == nil || ctx.Err() != nil
+	if failed || len(names) == 0 {
+		if err := c.checkConnection(); err != nil {
+			return nil, err
+		}
+	}
+	if failed {
+		c.pollFailureCountMetric.WithLabelValues(operation).Inc()
+	}
+
+	return names, nil
+}
+
+// checkConnection returns nats.ErrConnectionClosed when the connection of the JetStream context
+// is closed, which listers do not report.
+func (c *NatsJetStreamStateCollector) checkConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if _, err := c.js.AccountInfo(nats.Context(ctx)); errors.Is(err, nats.ErrConnectionClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type KeyValueMetricsCollector interface {
+	IncOperationCount(bucket, operation, outcome string)
+	ObserveOperationDuration(bucket, operation, outcome string, duration float64)
+	IncWatcherUpdateCount(bucket, operation string)
+	ObserveWatcherLag(bucket string, lag float64)
+}
+
+const (
+	NatsKeyValueOperationsTotal       = "kv_operations_total"
+	NatsKeyValueOperationsTotalHelp   = "Total number of NATS key-value store operations."
+	NatsKeyValueOperationDuration     = "kv_operation_duration_seconds"
+	NatsKeyValueOperationDurationHelp = "Duration of NATS key-value store operations."
+	NatsKeyValueWatcherUpdatesTotal   = "kv_watcher_updates_total"
+	NatsKeyValueWatcherUpdatesHelp    = "Total number of updates received by NATS key-value watchers."
+	NatsKeyValueWatcherLag            = "kv_watcher_lag_seconds"
+	NatsKeyValueWatcherLagHelp        = "Time between a NATS key-value update and its delivery to a watcher."
+
+	NatsBucketLabel    = "bucket"
+	NatsOperationLabel = "operation"
+
+	NatsKeyValueGetOperation    = "get"
+	NatsKeyValuePutOperation    = "put"
+	NatsKeyValueCreateOperation = "create"
+	NatsKeyValueUpdateOperation = "update"
+	NatsKeyValueDeleteOperation = "delete"
+	NatsKeyValuePurgeOperation  = "purge"
+
+	NatsKeyValueOutcomeOk                = "ok"
+	NatsKeyValueOutcomeKeyNotFound       = "key_not_found"
+	NatsKeyValueOutcomeWrongLastSequence = "wrong_last_sequence"
+	NatsKeyValueOutcomeTimeout           = "timeout"
+	NatsKeyValueOutcomeError             = "error"
+)
+
+var natsKeyValueMetricsCollector KeyValueMetricsCollector
+
+type NatsKeyValueMetricsCollector struct {
+	operationCountMetric     *prometheus.CounterVec
+	operationDurationMetric  *prometheus.HistogramVec
+	watcherUpdateCountMetric *prometheus.CounterVec
+	watcherLagMetric         *prometheus.HistogramVec
+}
+
+func NewNatsKeyValueMetricsCollector(reg *prometheus.Registry, serviceName string) KeyValueMetricsCollector {
+	operationCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsKeyValueOperationsTotal),
+			Help: NatsKeyValueOperationsTotalHelp,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel, NatsOutcomeLabel},
+	)
+
+	operationDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsKeyValueOperationDuration),
+			Help:    NatsKeyValueOperationDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel, NatsOutcomeLabel},
+	)
+
+	watcherUpdateCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsKeyValueWatcherUpdatesTotal),
+			Help: NatsKeyValueWatcherUpdatesHelp,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel},
+	)
+
+	watcherLagMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsKeyValueWatcherLag),
+			Help:    NatsKeyValueWatcherLagHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsBucketLabel},
+	)
+
+	reg.MustRegister(
+		operationCountMetric,
+		operationDurationMetric,
+		watcherUpdateCountMetric,
+		watcherLagMetric,
+	)
+
+	natsKeyValueMetricsCollector = &NatsKeyValueMetricsCollector{
+		operationCountMetric:     operationCountMetric,
+		operationDurationMetric:  operationDurationMetric,
+		watcherUpdateCountMetric: watcherUpdateCountMetric,
+		watcherLagMetric:         watcherLagMetric,
+	}
+
+	return natsKeyValueMetricsCollector
+}
+
+func GetNatsKeyValueMetricsCollector() (KeyValueMetricsCollector, error) {
+	if natsKeyValueMetricsCollector == nil {
+		return nil, errors.New("natsKeyValueMetricsCollector is nil")
+	}
+	return natsKeyValueMetricsCollector, nil
+}
+
+func (m *NatsKeyValueMetricsCollector) IncOperationCount(bucket, operation, outcome string) {
+	m.operationCountMetric.WithLabelValues(bucket, operation, outcome).Inc()
+}
+
+func (m *NatsKeyValueMetricsCollector) ObserveOperationDuration(bucket, operation, outcome string, duration float64) {
+	m.operationDurationMetric.WithLabelValues(bucket, operation, outcome).Observe(duration)
+}
+
+func (m *NatsKeyValueMetricsCollector) IncWatcherUpdateCount(bucket, operation string) {
+	m.watcherUpdateCountMetric.WithLabelValues(bucket, operation).Inc()
+}
+
+func (m *NatsKeyValueMetricsCollector) ObserveWatcherLag(bucket string, lag float64) {
+	m.watcherLagMetric.WithLabelValues(bucket).Observe(lag)
+}
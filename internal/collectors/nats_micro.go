@@ -0,0 +1,221 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+type MicroMetricsCollector interface {
+	TrackService(svc micro.Service)
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsMicroEndpointRequestsTotal         = "micro_endpoint_requests_total"
+	NatsMicroEndpointRequestsTotalHelp     = "Total number of requests reported by micro service endpoint stats."
+	NatsMicroEndpointErrorsTotal           = "micro_endpoint_errors_total"
+	NatsMicroEndpointErrorsTotalHelp       = "Total number of errors reported by micro service endpoint stats."
+	NatsMicroEndpointProcessingSeconds     = "micro_endpoint_processing_seconds_total"
+	NatsMicroEndpointProcessingSecondsHelp = "Total processing time reported by micro service endpoint stats."
+	NatsMicroEndpointAverageProcessing     = "micro_endpoint_average_processing_seconds"
+	NatsMicroEndpointAverageProcessingHelp = "Average processing time reported by micro service endpoint stats."
+	NatsMicroServiceInfo                   = "micro_service_info"
+	NatsMicroServiceInfoHelp               = "Information about the tracked micro services, always 1."
+	NatsMicroServiceStartTimeSeconds       = "micro_service_start_time_seconds"
+	NatsMicroServiceStartTimeSecondsHelp   = "Start time of the tracked micro services since the Unix epoch in seconds."
+
+	NatsEndpointLabel  = "endpoint"
+	NatsServiceLabel   = "service"
+	NatsServiceIdLabel = "service_id"
+	NatsVersionLabel   = "version"
+)
+
+var natsMicroMetricsCollector MicroMetricsCollector
+
+type microEndpointKey struct {
+	service  string
+	endpoint string
+	subject  string
+}
+
+type microEndpointStats struct {
+	requests       int
+	errors         int
+	processingTime float64
+}
+
+func (s microEndpointStats) add(other microEndpointStats) microEndpointStats {
+	return microEndpointStats{
+		requests:       s.requests + other.requests,
+		errors:         s.errors + other.errors,
+		processingTime: s.processingTime + other.processingTime,
+	}
+}
+
+// trackedMicroService remembers the endpoint stats of a service seen on the last scrape, which
+// are kept once the service is stopped or its stats are reset.
+type trackedMicroService struct {
+	svc  micro.Service
+	last map[microEndpointKey]microEndpointStats
+}
+
+type microServiceSnapshot struct {
+	stats     micro.Stats
+	endpoints map[microEndpointKey]microEndpointStats
+}
+
+type NatsMicroCollector struct {
+	mu           sync.Mutex
+	services     []*trackedMicroService
+	removedStats map[microEndpointKey]microEndpointStats
+
+	endpointRequestsDesc          *prometheus.Desc
+	endpointErrorsDesc            *prometheus.Desc
+	endpointProcessingTimeDesc    *prometheus.Desc
+	endpointAverageProcessingDesc *prometheus.Desc
+	serviceInfoDesc               *prometheus.Desc
+	serviceStartTimeDesc          *prometheus.Desc
+}
+
+func NewNatsMicroCollector(reg *prometheus.Registry, serviceName string) MicroMetricsCollector {
+	endpointLabels := []string{NatsServiceLabel, NatsEndpointLabel, NatsSubjectLabel}
+
+	collector := &NatsMicroCollector{
+		removedStats: make(map[microEndpointKey]microEndpointStats),
+		endpointRequestsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroEndpointRequestsTotal),
+			NatsMicroEndpointRequestsTotalHelp,
+			endpointLabels, nil,
+		),
+		endpointErrorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroEndpointErrorsTotal),
+			NatsMicroEndpointErrorsTotalHelp,
+			endpointLabels, nil,
+		),
+		endpointProcessingTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroEndpointProcessingSeconds),
+			NatsMicroEndpointProcessingSecondsHelp,
+			endpointLabels, nil,
+		),
+		endpointAverageProcessingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroEndpointAverageProcessing),
+			NatsMicroEndpointAverageProcessingHelp,
+			endpointLabels, nil,
+		),
+		serviceInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroServiceInfo),
+			NatsMicroServiceInfoHelp,
+			[]string{NatsServiceLabel, NatsServiceIdLabel, NatsVersionLabel}, nil,
+		),
+		serviceStartTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsMicroServiceStartTimeSeconds),
+			NatsMicroServiceStartTimeSecondsHelp,
+			[]string{NatsServiceLabel, NatsServiceIdLabel}, nil,
+		),
+	}
+
+	reg.MustRegister(collector)
+
+	natsMicroMetricsCollector = collector
+
+	return natsMicroMetricsCollector
+}
+
+func GetNatsMicroMetricsCollector() (MicroMetricsCollector, error) {
+	if natsMicroMetricsCollector == nil {
+		return nil, errors.New("natsMicroMetricsCollector is nil")
+	}
+	return natsMicroMetricsCollector, nil
+}
+
+// TrackService exports the endpoint stats and the info of a service on every scrape until
+// it is stopped. The endpoint stats of stopped services remain part of the totals.
+func (c *NatsMicroCollector) TrackService(svc micro.Service) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.services = append(c.services, &trackedMicroService{svc: svc})
+}
+
+func (c *NatsMicroCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	var snapshots []microServiceSnapshot
+	active := c.services[:0]
+	for _, tracked := range c.services {
+		if tracked.svc.Stopped() {
+			c.removeStats(tracked.last)
+			continue
+		}
+		active = append(active, tracked)
+
+		stats := tracked.svc.Stats()
+		endpoints := make(map[microEndpointKey]microEndpointStats, len(stats.Endpoints))
+		for _, endpoint := range stats.Endpoints {
+			key := microEndpointKey{service: stats.Name, endpoint: endpoint.Name, subject: endpoint.Subject}
+			endpoints[key] = microEndpointStats{
+				requests:       endpoint.NumRequests,
+				errors:         endpoint.NumErrors,
+				processingTime: endpoint.ProcessingTime.Seconds(),
+			}
+		}
+
+		// Service.Reset zeroes the endpoint stats
+		for key, last := range tracked.last {
+			if current, ok := endpoints[key]; !ok || current.requests < last.requests || current.errors < last.errors {
+				c.removedStats[key] = c.removedStats[key].add(last)
+			}
+		}
+		tracked.last = endpoints
+
+		snapshots = append(snapshots, microServiceSnapshot{stats: stats, endpoints: endpoints})
+	}
+	c.services = active
+
+	// Instances of the same service running in this process are aggregated
+	totals := make(map[microEndpointKey]microEndpointStats, len(c.removedStats))
+	for key, removed := range c.removedStats {
+		totals[key] = removed
+	}
+	c.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		ch <- prometheus.MustNewConstMetric(c.serviceInfoDesc, prometheus.GaugeValue, 1, snapshot.stats.Name, snapshot.stats.ID, snapshot.stats.Version)
+		ch <- prometheus.MustNewConstMetric(c.serviceStartTimeDesc, prometheus.GaugeValue, float64(snapshot.stats.Started.UnixNano())/1e9, snapshot.stats.Name, snapshot.stats.ID)
+
+		for key, s := range snapshot.endpoints {
+			totals[key] = totals[key].add(s)
+		}
+	}
+
+	for key, s := range totals {
+		var average float64
+		if s.requests > 0 {
+			average = s.processingTime / float64(s.requests)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.endpointRequestsDesc, prometheus.CounterValue, float64(s.requests), key.service, key.endpoint, key.subject)
+		ch <- prometheus.MustNewConstMetric(c.endpointErrorsDesc, prometheus.CounterValue, float64(s.errors), key.service, key.endpoint, key.subject)
+		ch <- prometheus.MustNewConstMetric(c.endpointProcessingTimeDesc, prometheus.CounterValue, s.processingTime, key.service, key.endpoint, key.subject)
+		ch <- prometheus.MustNewConstMetric(c.endpointAverageProcessingDesc, prometheus.GaugeValue, average, key.service, key.endpoint, key.subject)
+	}
+}
+
+// removeStats keeps the last seen endpoint stats of a stopped service in the totals. It must be
+// called with the mutex held.
+func (c *NatsMicroCollector) removeStats(last map[microEndpointKey]microEndpointStats) {
+	for key, s := range last {
+		c.removedStats[key] = c.removedStats[key].add(s)
+	}
+}
+
+func (c *NatsMicroCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.endpointRequestsDesc
+	ch <- c.endpointErrorsDesc
+	ch <- c.endpointProcessingTimeDesc
+	ch <- c.endpointAverageProcessingDesc
+	ch <- c.serviceInfoDesc
+	ch <- c.serviceStartTimeDesc
+}
@@ -0,0 +1,143 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ObjectStoreMetricsCollector interface {
+	IncOperationCount(bucket, operation, outcome string)
+	ObserveOperationDuration(bucket, operation, outcome string, duration float64)
+	AddTransferredBytes(bucket, operation string, size float64)
+	ObserveChunkCount(bucket, operation string, chunks float64)
+	ObserveThroughput(bucket, operation string, throughput float64)
+}
+
+const (
+	NatsObjectStoreOperationsTotal       = "object_store_operations_total"
+	NatsObjectStoreOperationsTotalHelp   = "Total number of NATS object store operations."
+	NatsObjectStoreOperationDuration     = "object_store_operation_duration_seconds"
+	NatsObjectStoreOperationDurationHelp = "Duration of NATS object store operations, including the transfer of the object."
+	NatsObjectStoreBytesTotal            = "object_store_bytes_total"
+	NatsObjectStoreBytesTotalHelp        = "Total number of bytes transferred to and from NATS object stores."
+	NatsObjectStoreChunks                = "object_store_chunks"
+	NatsObjectStoreChunksHelp            = "Number of chunks of objects transferred to and from NATS object stores."
+	NatsObjectStoreThroughput            = "object_store_throughput_bytes_per_second"
+	NatsObjectStoreThroughputHelp        = "Throughput of object transfers to and from NATS object stores."
+
+	NatsObjectStorePutOperation    = "put"
+	NatsObjectStoreGetOperation    = "get"
+	NatsObjectStoreDeleteOperation = "delete"
+
+	NatsObjectStoreOutcomeOk             = "ok"
+	NatsObjectStoreOutcomeObjectNotFound = "object_not_found"
+	NatsObjectStoreOutcomeError          = "error"
+	NatsObjectStoreOutcomeAborted        = "aborted"
+)
+
+var (
+	// NatsObjectStoreChunkBuckets range from 1 to 2048 chunks.
+	NatsObjectStoreChunkBuckets = prometheus.ExponentialBuckets(1, 2, 12)
+	// NatsObjectStoreThroughputBuckets range from 1KB/s to 256MB/s.
+	NatsObjectStoreThroughputBuckets = prometheus.ExponentialBuckets(1024, 4, 10)
+)
+
+var natsObjectStoreMetricsCollector ObjectStoreMetricsCollector
+
+type NatsObjectStoreMetricsCollector struct {
+	operationCountMetric    *prometheus.CounterVec
+	operationDurationMetric *prometheus.HistogramVec
+	bytesCountMetric        *prometheus.CounterVec
+	chunkCountMetric        *prometheus.HistogramVec
+	throughputMetric        *prometheus.HistogramVec
+}
+
+func NewNatsObjectStoreMetricsCollector(reg *prometheus.Registry, serviceName string) ObjectStoreMetricsCollector {
+	operationCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsObjectStoreOperationsTotal),
+			Help: NatsObjectStoreOperationsTotalHelp,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel, NatsOutcomeLabel},
+	)
+
+	operationDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsObjectStoreOperationDuration),
+			Help:    NatsObjectStoreOperationDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel, NatsOutcomeLabel},
+	)
+
+	bytesCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsObjectStoreBytesTotal),
+			Help: NatsObjectStoreBytesTotalHelp,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel},
+	)
+
+	chunkCountMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsObjectStoreChunks),
+			Help:    NatsObjectStoreChunksHelp,
+			Buckets: NatsObjectStoreChunkBuckets,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel},
+	)
+
+	throughputMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsObjectStoreThroughput),
+			Help:    NatsObjectStoreThroughputHelp,
+			Buckets: NatsObjectStoreThroughputBuckets,
+		},
+		[]string{NatsBucketLabel, NatsOperationLabel},
+	)
+
+	reg.MustRegister(
+		operationCountMetric,
+		operationDurationMetric,
+		bytesCountMetric,
+		chunkCountMetric,
+		throughputMetric,
+	)
+
+	natsObjectStoreMetricsCollector = &NatsObjectStoreMetricsCollector{
+		operationCountMetric:    operationCountMetric,
+		operationDurationMetric: operationDurationMetric,
+		bytesCountMetric:        bytesCountMetric,
+		chunkCountMetric:        chunkCountMetric,
+		throughputMetric:        throughputMetric,
+	}
+
+	return natsObjectStoreMetricsCollector
+}
+
+func GetNatsObjectStoreMetricsCollector() (ObjectStoreMetricsCollector, error) {
+	if natsObjectStoreMetricsCollector == nil {
+		return nil, errors.New("natsObjectStoreMetricsCollector is nil")
+	}
+	return natsObjectStoreMetricsCollector, nil
+}
+
+func (m *NatsObjectStoreMetricsCollector) IncOperationCount(bucket, operation, outcome string) {
+	m.operationCountMetric.WithLabelValues(bucket, operation, outcome).Inc()
+}
+
+func (m *NatsObjectStoreMetricsCollector) ObserveOperationDuration(bucket, operation, outcome string, duration float64) {
+	m.operationDurationMetric.WithLabelValues(bucket, operation, outcome).Observe(duration)
+}
+
+func (m *NatsObjectStoreMetricsCollector) AddTransferredBytes(bucket, operation string, size float64) {
+	m.bytesCountMetric.WithLabelValues(bucket, operation).Add(size)
+}
+
+func (m *NatsObjectStoreMetricsCollector) ObserveChunkCount(bucket, operation string, chunks float64) {
+	m.chunkCountMetric.WithLabelValues(bucket, operation).Observe(chunks)
+}
+
+func (m *NatsObjectStoreMetricsCollector) ObserveThroughput(bucket, operation string, throughput float64) {
+	m.throughputMetric.WithLabelValues(bucket, operation).Observe(throughput)
+}
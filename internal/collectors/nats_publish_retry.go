@@ -0,0 +1,90 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type PublishRetryMetricsCollector interface {
+	IncPublishAttemptCount(subject string)
+	IncPublishRetryCount(subject, reason string)
+	IncPublishGiveUpCount(subject, reason string)
+}
+
+const (
+	NatsJetStreamPublishAttemptsTotal     = "jetstream_publish_attempts_total"
+	NatsJetStreamPublishAttemptsTotalHelp = "Total number of JetStream publish attempts made by publishers with a retry policy."
+	NatsJetStreamPublishRetriesTotal      = "jetstream_publish_retries_total"
+	NatsJetStreamPublishRetriesTotalHelp  = "Total number of JetStream publishes retried after a retryable error."
+	NatsJetStreamPublishGiveUpsTotal      = "jetstream_publish_give_ups_total"
+	NatsJetStreamPublishGiveUpsTotalHelp  = "Total number of JetStream publishes that failed after exhausting their retry policy or with a non-retryable error."
+)
+
+var natsPublishRetryMetricsCollector PublishRetryMetricsCollector
+
+type NatsPublishRetryMetricsCollector struct {
+	attemptCountMetric *prometheus.CounterVec
+	retryCountMetric   *prometheus.CounterVec
+	giveUpCountMetric  *prometheus.CounterVec
+}
+
+func NewNatsPublishRetryMetricsCollector(reg *prometheus.Registry, serviceName string) PublishRetryMetricsCollector {
+	reasonLabels := []string{NatsSubjectLabel, NatsReasonLabel}
+
+	attemptCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamPublishAttemptsTotal),
+			Help: NatsJetStreamPublishAttemptsTotalHelp,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	retryCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamPublishRetriesTotal),
+			Help: NatsJetStreamPublishRetriesTotalHelp,
+		},
+		reasonLabels,
+	)
+
+	giveUpCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsJetStreamPublishGiveUpsTotal),
+			Help: NatsJetStreamPublishGiveUpsTotalHelp,
+		},
+		reasonLabels,
+	)
+
+	reg.MustRegister(
+		attemptCountMetric,
+		retryCountMetric,
+		giveUpCountMetric,
+	)
+
+	natsPublishRetryMetricsCollector = &NatsPublishRetryMetricsCollector{
+		attemptCountMetric: attemptCountMetric,
+		retryCountMetric:   retryCountMetric,
+		giveUpCountMetric:  giveUpCountMetric,
+	}
+
+	return natsPublishRetryMetricsCollector
+}
+
+func GetNatsPublishRetryMetricsCollector() (PublishRetryMetricsCollector, error) {
+	if natsPublishRetryMetricsCollector == nil {
+		return nil, errors.New("natsPublishRetryMetricsCollector is nil")
+	}
+	return natsPublishRetryMetricsCollector, nil
+}
+
+func (m *NatsPublishRetryMetricsCollector) IncPublishAttemptCount(subject string) {
+	m.attemptCountMetric.WithLabelValues(subject).Inc()
+}
+
+func (m *NatsPublishRetryMetricsCollector) IncPublishRetryCount(subject, reason string) {
+	m.retryCountMetric.WithLabelValues(subject, reason).Inc()
+}
+
+func (m *NatsPublishRetryMetricsCollector) IncPublishGiveUpCount(subject, reason string) {
+	m.giveUpCountMetric.WithLabelValues(subject, reason).Inc()
+}
@@ -0,0 +1,153 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type RequestReplyMetricsCollector interface {
+	IncRequestCount(subject, outcome string)
+	ObserveRequestDuration(subject, outcome string, duration float64)
+	IncRespondedRequestCount(subject string)
+	ObserveResponderHandlingDuration(subject string, duration float64)
+	ObserveRespondDuration(subject string, duration float64)
+	IncRespondErrorCount(subject string)
+}
+
+const (
+	NatsRequestsTotal              = "requests_total"
+	NatsRequestsTotalHelp          = "Total number of NATS requests sent."
+	NatsRequestDuration            = "request_duration_seconds"
+	NatsRequestDurationHelp        = "Round-trip duration of NATS requests."
+	NatsRespondedRequestsTotal     = "responded_requests_total"
+	NatsRespondedRequestsTotalHelp = "Total number of NATS requests handled by responders."
+	NatsResponderHandlingDuration  = "responder_handling_duration_seconds"
+	NatsResponderHandlingHelp      = "Duration of NATS request handling by responders."
+	NatsRespondDuration            = "respond_duration_seconds"
+	NatsRespondDurationHelp        = "Duration of sending NATS responses."
+	NatsRespondErrorsTotal         = "respond_errors_total"
+	NatsRespondErrorsTotalHelp     = "Total number of NATS responses that failed to be sent."
+
+	NatsOutcomeLabel = "outcome"
+
+	NatsRequestOutcomeOk           = "ok"
+	NatsRequestOutcomeTimeout      = "timeout"
+	NatsRequestOutcomeNoResponders = "no_responders"
+	NatsRequestOutcomeError        = "error"
+)
+
+var natsRequestReplyMetricsCollector RequestReplyMetricsCollector
+
+type NatsRequestReplyMetricsCollector struct {
+	requestCountMetric    *prometheus.CounterVec
+	requestDurationMetric *prometheus.HistogramVec
+
+	respondedRequestCountMetric     *prometheus.CounterVec
+	responderHandlingDurationMetric *prometheus.HistogramVec
+	respondDurationMetric           *prometheus.HistogramVec
+	respondErrorCountMetric         *prometheus.CounterVec
+}
+
+func NewNatsRequestReplyMetricsCollector(reg *prometheus.Registry, serviceName string) RequestReplyMetricsCollector {
+	requestCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsRequestsTotal),
+			Help: NatsRequestsTotalHelp,
+		},
+		[]string{NatsSubjectLabel, NatsOutcomeLabel},
+	)
+
+	requestDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsRequestDuration),
+			Help:    NatsRequestDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsSubjectLabel, NatsOutcomeLabel},
+	)
+
+	respondedRequestCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsRespondedRequestsTotal),
+			Help: NatsRespondedRequestsTotalHelp,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	responderHandlingDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsResponderHandlingDuration),
+			Help:    NatsResponderHandlingHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	respondDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsRespondDuration),
+			Help:    NatsRespondDurationHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	respondErrorCountMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsRespondErrorsTotal),
+			Help: NatsRespondErrorsTotalHelp,
+		},
+		[]string{NatsSubjectLabel},
+	)
+
+	reg.MustRegister(
+		requestCountMetric,
+		requestDurationMetric,
+		respondedRequestCountMetric,
+		responderHandlingDurationMetric,
+		respondDurationMetric,
+		respondErrorCountMetric,
+	)
+
+	natsRequestReplyMetricsCollector = &NatsRequestReplyMetricsCollector{
+		requestCountMetric:              requestCountMetric,
+		requestDurationMetric:           requestDurationMetric,
+		respondedRequestCountMetric:     respondedRequestCountMetric,
+		responderHandlingDurationMetric: responderHandlingDurationMetric,
+		respondDurationMetric:           respondDurationMetric,
+		respondErrorCountMetric:         respondErrorCountMetric,
+	}
+
+	return natsRequestReplyMetricsCollector
+}
+
+func GetNatsRequestReplyMetricsCollector() (RequestReplyMetricsCollector, error) {
+	if natsRequestReplyMetricsCollector == nil {
+		return nil, errors.New("natsRequestReplyMetricsCollector is nil")
+	}
+	return natsRequestReplyMetricsCollector, nil
+}
+
+func (m *NatsRequestReplyMetricsCollector) IncRequestCount(subject, outcome string) {
+	m.requestCountMetric.WithLabelValues(subject, outcome).Inc()
+}
+
+func (m *NatsRequestReplyMetricsCollector) ObserveRequestDuration(subject, outcome string, duration float64) {
+	m.requestDurationMetric.WithLabelValues(subject, outcome).Observe(duration)
+}
+
+func (m *NatsRequestReplyMetricsCollector) IncRespondedRequestCount(subject string) {
+	m.respondedRequestCountMetric.WithLabelValues(subject).Inc()
+}
+
+func (m *NatsRequestReplyMetricsCollector) ObserveResponderHandlingDuration(subject string, duration float64) {
+	m.responderHandlingDurationMetric.WithLabelValues(subject).Observe(duration)
+}
+
+func (m *NatsRequestReplyMetricsCollector) ObserveRespondDuration(subject string, duration float64) {
+	m.respondDurationMetric.WithLabelValues(subject).Observe(duration)
+}
+
+func (m *NatsRequestReplyMetricsCollector) IncRespondErrorCount(subject string) {
+	m.respondErrorCountMetric.WithLabelValues(subject).Inc()
+}
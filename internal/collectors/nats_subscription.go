@@ -0,0 +1,269 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+type NatsSubscriptionMetricsCollector interface {
+	TrackSubscription(nc *nats.Conn, sub *nats.Subscription, subject string)
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsSubscriptionPendingMessages        = "subscription_pending_messages"
+	NatsSubscriptionPendingMessagesHelp    = "Number of messages delivered to NATS subscriptions and pending processing."
+	NatsSubscriptionPendingBytes           = "subscription_pending_bytes"
+	NatsSubscriptionPendingBytesHelp       = "Number of bytes delivered to NATS subscriptions and pending processing."
+	NatsSubscriptionMaxPendingMessages     = "subscription_max_pending_messages"
+	NatsSubscriptionMaxPendingMessagesHelp = "Highest number of pending messages seen by NATS subscriptions."
+	NatsSubscriptionMaxPendingBytes        = "subscription_max_pending_bytes"
+	NatsSubscriptionMaxPendingBytesHelp    = "Highest number of pending bytes seen by NATS subscriptions."
+	NatsSubscriptionDroppedMessagesTotal   = "subscription_dropped_messages_total"
+	NatsSubscriptionDroppedMessagesHelp    = "Total number of messages dropped by NATS subscriptions because of slow consumption."
+	NatsSubscriptionDeliveredMessagesTotal = "subscription_delivered_messages_total"
+	NatsSubscriptionDeliveredMessagesHelp  = "Total number of messages delivered to NATS subscriptions."
+	NatsSubscriptionSlowConsumersTotal     = "subscription_slow_consumers_total"
+	NatsSubscriptionSlowConsumersTotalHelp = "Total number of slow consumer errors reported for NATS subscriptions."
+
+	NatsQueueLabel = "queue"
+)
+
+var natsSubscriptionMetricsCollector NatsSubscriptionMetricsCollector
+
+type trackedSubscription struct {
+	sub     *nats.Subscription
+	subject string
+
+	// Last dropped and delivered counts seen, which are no longer available once the
+	// subscription is unsubscribed
+	dropped   int
+	delivered int64
+}
+
+type subscriptionKey struct {
+	subject string
+	queue   string
+}
+
+type subscriptionStats struct {
+	active             bool
+	pendingMessages    int
+	pendingBytes       int
+	maxPendingMessages int
+	maxPendingBytes    int
+	dropped            int
+	delivered          int64
+}
+
+// subscriptionCounts holds the dropped and delivered counts of removed subscriptions, keeping
+// the exported counters monotonic once subscriptions go away.
+type subscriptionCounts struct {
+	dropped   int
+	delivered int64
+}
+
+type NatsSubscriptionCollector struct {
+	subjectNormalizer *SubjectNormalizer
+
+	mu            sync.Mutex
+	subscriptions []trackedSubscription
+	removedCounts map[subscriptionKey]subscriptionCounts
+	hookedConns   map[*nats.Conn]struct{}
+
+	pendingMessagesDesc    *prometheus.Desc
+	pendingBytesDesc       *prometheus.Desc
+	maxPendingMessagesDesc *prometheus.Desc
+	maxPendingBytesDesc    *prometheus.Desc
+	droppedDesc            *prometheus.Desc
+	deliveredDesc          *prometheus.Desc
+
+	slowConsumerCountMetric *prometheus.CounterVec
+}
+
+func NewNatsSubscriptionCollector(reg *prometheus.Registry, serviceName string, subjectNormalizer *SubjectNormalizer) NatsSubscriptionMetricsCollector {
+	subscriptionLabels := []string{NatsSubjectLabel, NatsQueueLabel}
+
+	collector := &NatsSubscriptionCollector{
+		subjectNormalizer: subjectNormalizer,
+		removedCounts:     make(map[subscriptionKey]subscriptionCounts),
+		hookedConns:       make(map[*nats.Conn]struct{}),
+		pendingMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionPendingMessages),
+			NatsSubscriptionPendingMessagesHelp,
+			subscriptionLabels, nil,
+		),
+		pendingBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionPendingBytes),
+			NatsSubscriptionPendingBytesHelp,
+			subscriptionLabels, nil,
+		),
+		maxPendingMessagesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionMaxPendingMessages),
+			NatsSubscriptionMaxPendingMessagesHelp,
+			subscriptionLabels, nil,
+		),
+		maxPendingBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionMaxPendingBytes),
+			NatsSubscriptionMaxPendingBytesHelp,
+			subscriptionLabels, nil,
+		),
+		droppedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionDroppedMessagesTotal),
+			NatsSubscriptionDroppedMessagesHelp,
+			subscriptionLabels, nil,
+		),
+		deliveredDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionDeliveredMessagesTotal),
+			NatsSubscriptionDeliveredMessagesHelp,
+			subscriptionLabels, nil,
+		),
+		slowConsumerCountMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionSlowConsumersTotal),
+				Help: NatsSubscriptionSlowConsumersTotalHelp,
+			},
+			subscriptionLabels,
+		),
+	}
+
+	reg.MustRegister(collector, collector.slowConsumerCountMetric)
+
+	natsSubscriptionMetricsCollector = collector
+
+	return natsSubscriptionMetricsCollector
+}
+
+func GetNatsSubscriptionMetricsCollector() (NatsSubscriptionMetricsCollector, error) {
+	if natsSubscriptionMetricsCollector == nil {
+		return nil, errors.New("natsSubscriptionMetricsCollector is nil")
+	}
+	return natsSubscriptionMetricsCollector, nil
+}
+
+// TrackSubscription exports the pending, dropped and delivered counts of a subscription
+// under the given subject until it is unsubscribed, and counts slow consumer errors
+// reported on its connection.
+func (c *NatsSubscriptionCollector) TrackSubscription(nc *nats.Conn, sub *nats.Subscription, subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscriptions = append(c.subscriptions, trackedSubscription{
+		sub:     sub,
+		subject: c.subjectNormalizer.Normalize(subject),
+	})
+
+	if _, ok := c.hookedConns[nc]; ok {
+		return
+	}
+	c.hookedConns[nc] = struct{}{}
+
+	errorHandler := nc.ErrorHandler()
+	nc.SetErrorHandler(func(conn *nats.Conn, sub *nats.Subscription, err error) {
+		if sub != nil && errors.Is(err, nats.ErrSlowConsumer) {
+			c.slowConsumerCountMetric.WithLabelValues(c.subscriptionSubject(sub), sub.Queue).Inc()
+		}
+		if errorHandler != nil {
+			errorHandler(conn, sub, err)
+		}
+	})
+}
+
+func (c *NatsSubscriptionCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	stats := make(map[subscriptionKey]*subscriptionStats)
+	active := c.subscriptions[:0]
+	for _, tracked := range c.subscriptions {
+		key := subscriptionKey{subject: tracked.subject, queue: tracked.sub.Queue}
+		if !tracked.sub.IsValid() {
+			// Fold the last counts seen into the totals of removed subscriptions
+			removed := c.removedCounts[key]
+			removed.dropped += tracked.dropped
+			removed.delivered += tracked.delivered
+			c.removedCounts[key] = removed
+			continue
+		}
+
+		s, ok := stats[key]
+		if !ok {
+			s = &subscriptionStats{}
+			stats[key] = s
+		}
+		addSubscriptionStats(s, &tracked)
+		active = append(active, tracked)
+	}
+	c.subscriptions = active
+
+	for key, removed := range c.removedCounts {
+		s, ok := stats[key]
+		if !ok {
+			s = &subscriptionStats{}
+			stats[key] = s
+		}
+		s.dropped += removed.dropped
+		s.delivered += removed.delivered
+	}
+	c.mu.Unlock()
+
+	for key, s := range stats {
+		if s.active {
+			ch <- prometheus.MustNewConstMetric(c.pendingMessagesDesc, prometheus.GaugeValue, float64(s.pendingMessages), key.subject, key.queue)
+			ch <- prometheus.MustNewConstMetric(c.pendingBytesDesc, prometheus.GaugeValue, float64(s.pendingBytes), key.subject, key.queue)
+			ch <- prometheus.MustNewConstMetric(c.maxPendingMessagesDesc, prometheus.GaugeValue, float64(s.maxPendingMessages), key.subject, key.queue)
+			ch <- prometheus.MustNewConstMetric(c.maxPendingBytesDesc, prometheus.GaugeValue, float64(s.maxPendingBytes), key.subject, key.queue)
+		}
+		ch <- prometheus.MustNewConstMetric(c.droppedDesc, prometheus.CounterValue, float64(s.dropped), key.subject, key.queue)
+		ch <- prometheus.MustNewConstMetric(c.deliveredDesc, prometheus.CounterValue, float64(s.delivered), key.subject, key.queue)
+	}
+}
+
+func (c *NatsSubscriptionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pendingMessagesDesc
+	ch <- c.pendingBytesDesc
+	ch <- c.maxPendingMessagesDesc
+	ch <- c.maxPendingBytesDesc
+	ch <- c.droppedDesc
+	ch <- c.deliveredDesc
+}
+
+// subscriptionSubject returns the tracked subject of a subscription, falling back to the
+// normalized subscription subject for subscriptions that are not tracked.
+func (c *NatsSubscriptionCollector) subscriptionSubject(sub *nats.Subscription) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tracked := range c.subscriptions {
+		if tracked.sub == sub {
+			return tracked.subject
+		}
+	}
+
+	return c.subjectNormalizer.Normalize(sub.Subject)
+}
+
+// addSubscriptionStats aggregates the statistics of subscriptions sharing the same labels.
+// Pending, dropped and delivered counts are summed while high-water marks keep the maximum.
+// The dropped and delivered counts are remembered on the tracked subscription.
+func addSubscriptionStats(s *subscriptionStats, tracked *trackedSubscription) {
+	sub := tracked.sub
+	s.active = true
+	if msgs, bytes, err := sub.Pending(); err == nil {
+		s.pendingMessages += msgs
+		s.pendingBytes += bytes
+	}
+	if msgs, bytes, err := sub.MaxPending(); err == nil {
+		s.maxPendingMessages = max(s.maxPendingMessages, msgs)
+		s.maxPendingBytes = max(s.maxPendingBytes, bytes)
+	}
+	if dropped, err := sub.Dropped(); err == nil {
+		tracked.dropped = dropped
+	}
+	if delivered, err := sub.Delivered(); err == nil {
+		tracked.delivered = delivered
+	}
+	s.dropped += tracked.dropped
+	s.delivered += tracked.delivered
+}
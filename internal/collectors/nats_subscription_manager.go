@@ -0,0 +1,141 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+type SubscriptionManagerMetricsCollector interface {
+	AddSubscription(sub *nats.Subscription, subject string)
+	ObserveDrainDuration(duration float64)
+	IncDrainProcessedMessageCount(subject, queue string)
+	IncDrainTimeoutCount()
+	Collect(ch chan<- prometheus.Metric)
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+const (
+	NatsManagedSubscriptionsActive              = "managed_subscriptions_active"
+	NatsManagedSubscriptionsActiveHelp          = "Number of active NATS subscriptions owned by the subscription manager."
+	NatsSubscriptionDrainDuration               = "subscription_drain_duration_seconds"
+	NatsSubscriptionDrainDurationHelp           = "Duration of draining the managed NATS subscriptions on shutdown."
+	NatsSubscriptionDrainProcessedMessagesTotal = "subscription_drain_processed_messages_total"
+	NatsSubscriptionDrainProcessedMessagesHelp  = "Total number of messages processed by managed NATS subscriptions while draining."
+	NatsSubscriptionDrainTimeoutsTotal          = "subscription_drain_timeouts_total"
+	NatsSubscriptionDrainTimeoutsTotalHelp      = "Total number of drains of the managed NATS subscriptions that did not complete in time."
+)
+
+var natsSubscriptionManagerMetricsCollector SubscriptionManagerMetricsCollector
+
+type NatsSubscriptionManagerCollector struct {
+	subjectNormalizer *SubjectNormalizer
+
+	mu            sync.Mutex
+	subscriptions []trackedSubscription
+
+	activeSubscriptionsDesc *prometheus.Desc
+
+	drainDurationMetric              prometheus.Histogram
+	drainProcessedMessageCountMetric *prometheus.CounterVec
+	drainTimeoutCountMetric          prometheus.Counter
+}
+
+func NewNatsSubscriptionManagerCollector(reg *prometheus.Registry, serviceName string, subjectNormalizer *SubjectNormalizer) SubscriptionManagerMetricsCollector {
+	subscriptionLabels := []string{NatsSubjectLabel, NatsQueueLabel}
+
+	collector := &NatsSubscriptionManagerCollector{
+		subjectNormalizer: subjectNormalizer,
+		activeSubscriptionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(serviceName, NatsSubsystem, NatsManagedSubscriptionsActive),
+			NatsManagedSubscriptionsActiveHelp,
+			subscriptionLabels, nil,
+		),
+		drainDurationMetric: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionDrainDuration),
+				Help:    NatsSubscriptionDrainDurationHelp,
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+		drainProcessedMessageCountMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionDrainProcessedMessagesTotal),
+				Help: NatsSubscriptionDrainProcessedMessagesHelp,
+			},
+			subscriptionLabels,
+		),
+		drainTimeoutCountMetric: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsSubscriptionDrainTimeoutsTotal),
+				Help: NatsSubscriptionDrainTimeoutsTotalHelp,
+			},
+		),
+	}
+
+	reg.MustRegister(
+		collector,
+		collector.drainDurationMetric,
+		collector.drainProcessedMessageCountMetric,
+		collector.drainTimeoutCountMetric,
+	)
+
+	natsSubscriptionManagerMetricsCollector = collector
+
+	return natsSubscriptionManagerMetricsCollector
+}
+
+func GetNatsSubscriptionManagerMetricsCollector() (SubscriptionManagerMetricsCollector, error) {
+	if natsSubscriptionManagerMetricsCollector == nil {
+		return nil, errors.New("natsSubscriptionManagerMetricsCollector is nil")
+	}
+	return natsSubscriptionManagerMetricsCollector, nil
+}
+
+// AddSubscription counts a managed subscription as active under the given subject until it is
+// unsubscribed or drained.
+func (c *NatsSubscriptionManagerCollector) AddSubscription(sub *nats.Subscription, subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscriptions = append(c.subscriptions, trackedSubscription{
+		sub:     sub,
+		subject: c.subjectNormalizer.Normalize(subject),
+	})
+}
+
+func (c *NatsSubscriptionManagerCollector) ObserveDrainDuration(duration float64) {
+	c.drainDurationMetric.Observe(duration)
+}
+
+func (c *NatsSubscriptionManagerCollector) IncDrainProcessedMessageCount(subject, queue string) {
+	c.drainProcessedMessageCountMetric.WithLabelValues(subject, queue).Inc()
+}
+
+func (c *NatsSubscriptionManagerCollector) IncDrainTimeoutCount() {
+	c.drainTimeoutCountMetric.Inc()
+}
+
+func (c *NatsSubscriptionManagerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	counts := make(map[subscriptionKey]int)
+	active := c.subscriptions[:0]
+	for _, tracked := range c.subscriptions {
+		if !tracked.sub.IsValid() {
+			continue
+		}
+		active = append(active, tracked)
+		counts[subscriptionKey{subject: tracked.subject, queue: tracked.sub.Queue}]++
+	}
+	c.subscriptions = active
+	c.mu.Unlock()
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.activeSubscriptionsDesc, prometheus.GaugeValue, float64(count), key.subject, key.queue)
+	}
+}
+
+func (c *NatsSubscriptionManagerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeSubscriptionsDesc
+}
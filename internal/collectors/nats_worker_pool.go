@@ -0,0 +1,156 @@
+package collectors
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+type WorkerPoolMetricsCollector interface {
+	AddWorkers(subject, queue string, workers int)
+	IncInFlightMessages(subject, queue string)
+	DecInFlightMessages(subject, queue string)
+	ObserveQueueWaitDuration(subject, queue string, duration float64)
+}
+
+const (
+	NatsWorkerPoolWorkers              = "worker_pool_workers"
+	NatsWorkerPoolWorkersHelp          = "Number of workers processing messages of NATS subscriptions."
+	NatsWorkerPoolInFlightMessages     = "worker_pool_in_flight_messages"
+	NatsWorkerPoolInFlightMessagesHelp = "Number of messages currently processed by the workers of NATS subscriptions."
+	NatsWorkerPoolUtilization          = "worker_pool_utilization"
+	NatsWorkerPoolUtilizationHelp      = "Ratio of busy workers to all workers of NATS subscriptions."
+	NatsWorkerPoolQueueWait            = "worker_pool_queue_wait_seconds"
+	NatsWorkerPoolQueueWaitHelp        = "Time messages spent waiting for a free worker after being received."
+)
+
+var natsWorkerPoolMetricsCollector WorkerPoolMetricsCollector
+
+type workerPoolKey struct {
+	subject string
+	queue   string
+}
+
+type workerPoolState struct {
+	workers  int
+	inFlight int
+}
+
+type NatsWorkerPoolMetricsCollector struct {
+	mu    sync.Mutex
+	pools map[workerPoolKey]*workerPoolState
+
+	workersMetric           *prometheus.GaugeVec
+	inFlightMessagesMetric  *prometheus.GaugeVec
+	utilizationMetric       *prometheus.GaugeVec
+	queueWaitDurationMetric *prometheus.HistogramVec
+}
+
+func NewNatsWorkerPoolMetricsCollector(reg *prometheus.Registry, serviceName string) WorkerPoolMetricsCollector {
+	poolLabels := []string{NatsSubjectLabel, NatsQueueLabel}
+
+	workersMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsWorkerPoolWorkers),
+			Help: NatsWorkerPoolWorkersHelp,
+		},
+		poolLabels,
+	)
+
+	inFlightMessagesMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsWorkerPoolInFlightMessages),
+			Help: NatsWorkerPoolInFlightMessagesHelp,
+		},
+		poolLabels,
+	)
+
+	utilizationMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(serviceName, NatsSubsystem, NatsWorkerPoolUtilization),
+			Help: NatsWorkerPoolUtilizationHelp,
+		},
+		poolLabels,
+	)
+
+	queueWaitDurationMetric := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    prometheus.BuildFQName(serviceName, NatsSubsystem, NatsWorkerPoolQueueWait),
+			Help:    NatsWorkerPoolQueueWaitHelp,
+			Buckets: prometheus.DefBuckets,
+		},
+		poolLabels,
+	)
+
+	reg.MustRegister(
+		workersMetric,
+		inFlightMessagesMetric,
+		utilizationMetric,
+		queueWaitDurationMetric,
+	)
+
+	natsWorkerPoolMetricsCollector = &NatsWorkerPoolMetricsCollector{
+		pools:                   make(map[workerPoolKey]*workerPoolState),
+		workersMetric:           workersMetric,
+		inFlightMessagesMetric:  inFlightMessagesMetric,
+		utilizationMetric:       utilizationMetric,
+		queueWaitDurationMetric: queueWaitDurationMetric,
+	}
+
+	return natsWorkerPoolMetricsCollector
+}
+
+func GetNatsWorkerPoolMetricsCollector() (WorkerPoolMetricsCollector, error) {
+	if natsWorkerPoolMetricsCollector == nil {
+		return nil, errors.New("natsWorkerPoolMetricsCollector is nil")
+	}
+	return natsWorkerPoolMetricsCollector, nil
+}
+
+// AddWorkers adds workers to the pools of a subject and queue group, or removes them when
+// workers is negative.
+func (m *NatsWorkerPoolMetricsCollector) AddWorkers(subject, queue string, workers int) {
+	m.update(subject, queue, func(s *workerPoolState) {
+		s.workers += workers
+	})
+}
+
+func (m *NatsWorkerPoolMetricsCollector) IncInFlightMessages(subject, queue string) {
+	m.update(subject, queue, func(s *workerPoolState) {
+		s.inFlight++
+	})
+}
+
+func (m *NatsWorkerPoolMetricsCollector) DecInFlightMessages(subject, queue string) {
+	m.update(subject, queue, func(s *workerPoolState) {
+		s.inFlight--
+	})
+}
+
+func (m *NatsWorkerPoolMetricsCollector) ObserveQueueWaitDuration(subject, queue string, duration float64) {
+	m.queueWaitDurationMetric.WithLabelValues(subject, queue).Observe(duration)
+}
+
+// update applies a change to the pools sharing a subject and queue group and exports their
+// combined state, so that the utilization of several pools is not overwritten by each other.
+func (m *NatsWorkerPoolMetricsCollector) update(subject, queue string, change func(s *workerPoolState)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := workerPoolKey{subject: subject, queue: queue}
+	s, ok := m.pools[key]
+	if !ok {
+		s = &workerPoolState{}
+		m.pools[key] = s
+	}
+	change(s)
+
+	var utilization float64
+	if s.workers > 0 {
+		utilization = float64(s.inFlight) / float64(s.workers)
+	}
+
+	m.workersMetric.WithLabelValues(subject, queue).Set(float64(s.workers))
+	m.inFlightMessagesMetric.WithLabelValues(subject, queue).Set(float64(s.inFlight))
+	m.utilizationMetric.WithLabelValues(subject, queue).Set(utilization)
+}
@@ -0,0 +1,110 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"strings"
+)
+
+const (
+	natsSubjectTokenSeparator = "."
+	natsSingleTokenWildcard   = "*"
+	natsFullWildcard          = ">"
+
+	// NatsInboxSubject is the label value all _INBOX. reply subjects are collapsed to.
+	NatsInboxSubject = nats.InboxPrefix + natsFullWildcard
+)
+
+var subjectNormalizer *SubjectNormalizer
+
+// SubjectNormalizer maps concrete NATS subjects onto a bounded set of label values.
+type SubjectNormalizer struct {
+	patterns               [][]string
+	useSubscriptionSubject bool
+}
+
+// NewSubjectNormalizer creates a SubjectNormalizer from subject patterns using the * and >
+// wildcards. Patterns are matched in declaration order and the first match wins.
+func NewSubjectNormalizer(patterns []string, useSubscriptionSubject bool) *SubjectNormalizer {
+	tokenizedPatterns := make([][]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		tokens, err := tokenizeSubjectPattern(pattern)
+		if err != nil {
+			panic(err)
+		}
+		tokenizedPatterns = append(tokenizedPatterns, tokens)
+	}
+
+	subjectNormalizer = &SubjectNormalizer{
+		patterns:               tokenizedPatterns,
+		useSubscriptionSubject: useSubscriptionSubject,
+	}
+
+	return subjectNormalizer
+}
+
+func GetSubjectNormalizer() (*SubjectNormalizer, error) {
+	if subjectNormalizer == nil {
+		return nil, errors.New("subjectNormalizer is nil")
+	}
+	return subjectNormalizer, nil
+}
+
+// Normalize returns the label value for a subject: reply inboxes are collapsed, subjects
+// matching a pattern are replaced by the pattern and any other subject is kept as is.
+func (n *SubjectNormalizer) Normalize(subject string) string {
+	if strings.HasPrefix(subject, nats.InboxPrefix) {
+		return NatsInboxSubject
+	}
+
+	tokens := strings.Split(subject, natsSubjectTokenSeparator)
+	for _, pattern := range n.patterns {
+		if matchSubjectTokens(pattern, tokens) {
+			return strings.Join(pattern, natsSubjectTokenSeparator)
+		}
+	}
+
+	return subject
+}
+
+// MessageSubject returns the normalized label value for a received message. When configured
+// to, it prefers the subject filter of the message's subscription, unless that is an inbox
+// as is the case for JetStream push and pull consumers.
+func (n *SubjectNormalizer) MessageSubject(msg *nats.Msg) string {
+	if n.useSubscriptionSubject && msg.Sub != nil && !strings.HasPrefix(msg.Sub.Subject, nats.InboxPrefix) {
+		return n.Normalize(msg.Sub.Subject)
+	}
+
+	return n.Normalize(msg.Subject)
+}
+
+func tokenizeSubjectPattern(pattern string) ([]string, error) {
+	tokens := strings.Split(pattern, natsSubjectTokenSeparator)
+	for i, token := range tokens {
+		if token == "" {
+			return nil, fmt.Errorf("invalid subject pattern %q: empty token", pattern)
+		}
+		if token == natsFullWildcard && i != len(tokens)-1 {
+			return nil, fmt.Errorf("invalid subject pattern %q: %s must be the last token", pattern, natsFullWildcard)
+		}
+	}
+
+	return tokens, nil
+}
+
+func matchSubjectTokens(pattern, tokens []string) bool {
+	for i, patternToken := range pattern {
+		if patternToken == natsFullWildcard {
+			return len(tokens) > i
+		}
+		if i >= len(tokens) {
+			return false
+		}
+		if patternToken != natsSingleTokenWildcard && patternToken != tokens[i] {
+			return false
+		}
+	}
+
+	return len(pattern) == len(tokens)
+}
@@ -0,0 +1,70 @@
+package collectors
+
+import (
+	"testing"
+)
+
+func TestTokenizeSubjectPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{"literal", "orders.created", false},
+		{"single token wildcard", "orders.*.created", false},
+		{"trailing full wildcard", "orders.>", false},
+		{"full wildcard only", ">", false},
+		{"full wildcard not last", "orders.>.created", true},
+		{"empty token", "orders..created", true},
+		{"empty pattern", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokenizeSubjectPattern(tt.pattern); (err != nil) != tt.wantErr {
+				t.Errorf("tokenizeSubjectPattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSubjectNormalizerRejectsInvalidPatterns(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewSubjectNormalizer() did not panic for a pattern with > not in last position")
+		}
+	}()
+
+	NewSubjectNormalizer([]string{"orders.>.created"}, false)
+}
+
+func TestSubjectNormalizerNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		subject  string
+		want     string
+	}{
+		{"single token wildcard", []string{"orders.*.created"}, "orders.42.created", "orders.*.created"},
+		{"single token wildcard mismatch", []string{"orders.*.created"}, "orders.42.shipped", "orders.42.shipped"},
+		{"trailing full wildcard", []string{"orders.>"}, "orders.42.created", "orders.>"},
+		{"trailing full wildcard single token", []string{"orders.>"}, "orders.42", "orders.>"},
+		{"trailing full wildcard needs a token", []string{"orders.>"}, "orders", "orders"},
+		{"fewer tokens than pattern", []string{"orders.*.created"}, "orders.42", "orders.42"},
+		{"more tokens than pattern", []string{"orders.*"}, "orders.42.created", "orders.42.created"},
+		{"first pattern wins", []string{"orders.>", "orders.*.created"}, "orders.42.created", "orders.>"},
+		{"later pattern after mismatch", []string{"payments.>", "orders.*.created"}, "orders.42.created", "orders.*.created"},
+		{"inbox collapsed", nil, "_INBOX.abc123.def", NatsInboxSubject},
+		{"inbox collapsed before patterns", []string{">"}, "_INBOX.abc123", NatsInboxSubject},
+		{"unmatched kept", nil, "orders.42.created", "orders.42.created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewSubjectNormalizer(tt.patterns, false)
+			if got := n.Normalize(tt.subject); got != tt.want {
+				t.Errorf("Normalize(%q) with patterns %v = %q, want %q", tt.subject, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
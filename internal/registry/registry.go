@@ -1,8 +1,10 @@
 package registry
 
 import (
+	"github.com/nats-io/nats.go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
 )
 
 type MetricsRegistry struct {
@@ -10,17 +12,81 @@ type MetricsRegistry struct {
 	HttpMetricsCollector   collectors.HttpMetricsCollector
 	NatsMetricsCollector   collectors.AsyncMessageBrokerMetricsCollector
 	SystemMetricsCollector collectors.SystemMetricsCollector
+	SubjectNormalizer      *collectors.SubjectNormalizer
+	HeaderLabeler          *collectors.HeaderLabeler
+
+	NatsRequestReplyMetricsCollector collectors.RequestReplyMetricsCollector
+	NatsConnectionMetricsCollector   collectors.NatsConnectionMetricsCollector
+	NatsSubscriptionMetricsCollector collectors.NatsSubscriptionMetricsCollector
+	NatsAsyncPublishMetricsCollector collectors.AsyncPublishMetricsCollector
+
+	NatsJetStreamConsumerMetricsCollector collectors.JetStreamConsumerMetricsCollector
+	NatsKeyValueMetricsCollector          collectors.KeyValueMetricsCollector
+	NatsObjectStoreMetricsCollector       collectors.ObjectStoreMetricsCollector
+	NatsMicroMetricsCollector             collectors.MicroMetricsCollector
+	NatsJetStreamStateMetricsCollector    collectors.JetStreamStateMetricsCollector
+	NatsJetStreamAdvisoryMetricsCollector collectors.JetStreamAdvisoryMetricsCollector
+	NatsWorkerPoolMetricsCollector        collectors.WorkerPoolMetricsCollector
+	NatsHandlerMetricsCollector           collectors.HandlerMetricsCollector
+
+	NatsSubscriptionManagerMetricsCollector collectors.SubscriptionManagerMetricsCollector
+	NatsCodecMetricsCollector               collectors.CodecMetricsCollector
+	NatsActivityMetricsCollector            collectors.ActivityMetricsCollector
+	NatsPublishRetryMetricsCollector        collectors.PublishRetryMetricsCollector
+	NatsCircuitBreakerMetricsCollector      collectors.CircuitBreakerMetricsCollector
+	NatsBatchPublishMetricsCollector        collectors.BatchPublishMetricsCollector
+}
+
+type NatsOptions struct {
+	SubjectPatterns        []string
+	UseSubscriptionSubject bool
+	Connections            []*nats.Conn
+	RTTInterval            time.Duration
+	JetStream              nats.JetStreamContext
+	JetStreamStreams       map[string][]string
+	JetStreamPollInterval  time.Duration
+	JetStreamPollTimeout   time.Duration
+	HeaderLabels           []collectors.HeaderLabel
+	IdleThreshold          time.Duration
+	ExpectedProcessed      []string
+	ExpectedPublished      []string
 }
 
-func NewPrometheusRegistry(serviceName, metricsUrl string) *MetricsRegistry {
+func NewPrometheusRegistry(serviceName, metricsUrl string, natsOptions NatsOptions) *MetricsRegistry {
 	registry := prometheus.NewRegistry()
 
 	formattedServiceName := toSnakeCase(serviceName)
 
+	subjectNormalizer := collectors.NewSubjectNormalizer(natsOptions.SubjectPatterns, natsOptions.UseSubscriptionSubject)
+	headerLabeler := collectors.NewHeaderLabeler(natsOptions.HeaderLabels)
+
 	return &MetricsRegistry{
 		Registry:               registry,
 		HttpMetricsCollector:   collectors.NewFiberMetricsCollector(registry, formattedServiceName, metricsUrl),
-		NatsMetricsCollector:   collectors.NewNatsMetricsCollector(registry, formattedServiceName),
+		NatsMetricsCollector:   collectors.NewNatsMetricsCollector(registry, formattedServiceName, headerLabeler.Names()),
 		SystemMetricsCollector: collectors.NewODSystemMetricsCollector(registry, formattedServiceName),
+		SubjectNormalizer:      subjectNormalizer,
+		HeaderLabeler:          headerLabeler,
+
+		NatsRequestReplyMetricsCollector: collectors.NewNatsRequestReplyMetricsCollector(registry, formattedServiceName),
+		NatsConnectionMetricsCollector:   collectors.NewNatsConnectionCollector(registry, formattedServiceName, natsOptions.Connections, natsOptions.RTTInterval),
+		NatsSubscriptionMetricsCollector: collectors.NewNatsSubscriptionCollector(registry, formattedServiceName, subjectNormalizer),
+		NatsAsyncPublishMetricsCollector: collectors.NewNatsAsyncPublishMetricsCollector(registry, formattedServiceName),
+
+		NatsJetStreamConsumerMetricsCollector: collectors.NewNatsJetStreamConsumerMetricsCollector(registry, formattedServiceName),
+		NatsKeyValueMetricsCollector:          collectors.NewNatsKeyValueMetricsCollector(registry, formattedServiceName),
+		NatsObjectStoreMetricsCollector:       collectors.NewNatsObjectStoreMetricsCollector(registry, formattedServiceName),
+		NatsMicroMetricsCollector:             collectors.NewNatsMicroCollector(registry, formattedServiceName),
+		NatsJetStreamStateMetricsCollector:    collectors.NewNatsJetStreamStateCollector(registry, formattedServiceName, natsOptions.JetStream, natsOptions.JetStreamStreams, natsOptions.JetStreamPollInterval, natsOptions.JetStreamPollTimeout),
+		NatsJetStreamAdvisoryMetricsCollector: collectors.NewNatsJetStreamAdvisoryMetricsCollector(registry, formattedServiceName),
+		NatsWorkerPoolMetricsCollector:        collectors.NewNatsWorkerPoolMetricsCollector(registry, formattedServiceName),
+		NatsHandlerMetricsCollector:           collectors.NewNatsHandlerMetricsCollector(registry, formattedServiceName),
+
+		NatsSubscriptionManagerMetricsCollector: collectors.NewNatsSubscriptionManagerCollector(registry, formattedServiceName, subjectNormalizer),
+		NatsCodecMetricsCollector:               collectors.NewNatsCodecMetricsCollector(registry, formattedServiceName),
+		NatsActivityMetricsCollector:            collectors.NewNatsActivityCollector(registry, formattedServiceName, natsOptions.IdleThreshold, natsOptions.ExpectedProcessed, natsOptions.ExpectedPublished),
+		NatsPublishRetryMetricsCollector:        collectors.NewNatsPublishRetryMetricsCollector(registry, formattedServiceName),
+		NatsCircuitBreakerMetricsCollector:      collectors.NewNatsCircuitBreakerMetricsCollector(registry, formattedServiceName),
+		NatsBatchPublishMetricsCollector:        collectors.NewNatsBatchPublishMetricsCollector(registry, formattedServiceName),
 	}
 }
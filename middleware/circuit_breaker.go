@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenTimeout      = 30 * time.Second
+	defaultCircuitBreakerHalfOpenRequests = 1
+)
+
+// ErrCircuitOpen is returned by publishers guarded by an open circuit breaker without publishing
+// the message. It is a fiber.Error with status 503, so Fiber handlers may return it as is.
+var ErrCircuitOpen = fiber.NewError(fiber.StatusServiceUnavailable, "circuit breaker is open")
+
+// CircuitBreakerConfig configures the thresholds of a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed publishes opening the circuit,
+	// defaulting to 5.
+	FailureThreshold int
+	// OpenTimeout is the time the circuit stays open before letting trial publishes through,
+	// defaulting to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial publishes let through while half-open, all of
+	// which must succeed to close the circuit again, defaulting to 1.
+	HalfOpenRequests int
+	// IsFailure classifies the publish errors counting as failures, defaulting to
+	// IsCircuitBreakerFailure. Other errors, such as nats.ErrMaxPayload, are caused by the
+	// message rather than NATS and neither count as failures nor reset the failure count.
+	IsFailure func(error) bool
+}
+
+// IsCircuitBreakerFailure reports whether a publish failed because NATS is unavailable, which
+// covers the retryable JetStream errors of IsRetryablePublishError as well as the errors core
+// publishes fail with while the connection is closed, draining or reconnecting.
+func IsCircuitBreakerFailure(err error) bool {
+	return IsRetryablePublishError(err) ||
+		errors.Is(err, nats.ErrConnectionClosed) ||
+		errors.Is(err, nats.ErrConnectionDraining) ||
+		errors.Is(err, nats.ErrReconnectBufExceeded)
+}
+
+// CircuitBreaker fails publishes fast with ErrCircuitOpen once NATS is failing, instead of
+// letting callers block on a publish that is unlikely to succeed. It is shared by the publishers
+// configured with WithCircuitBreaker.
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+
+	mu                sync.Mutex
+	state             string
+	generation        uint64
+	failures          int
+	openedAt          time.Time
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker whose metrics are labeled with the name.
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	bc, err := collectors.GetNatsCircuitBreakerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = defaultCircuitBreakerFailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultCircuitBreakerOpenTimeout
+	}
+	if config.HalfOpenRequests < 1 {
+		config.HalfOpenRequests = defaultCircuitBreakerHalfOpenRequests
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = IsCircuitBreakerFailure
+	}
+
+	bc.SetCircuitBreakerState(name, collectors.NatsCircuitBreakerClosed)
+
+	return &CircuitBreaker{name: name, config: config, state: collectors.NatsCircuitBreakerClosed}
+}
+
+// State returns the state of the circuit breaker, which is one of "closed", "open" or
+// "half_open". An open circuit only becomes half-open with the next publish after OpenTimeout.
+func (cb *CircuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
+
+// guard runs the publish unless the circuit is open, and records its outcome. A nil circuit
+// breaker runs every publish.
+func (cb *CircuitBreaker) guard(publish func() error) error {
+	if cb == nil {
+		return publish()
+	}
+
+	generation, err := cb.allow()
+	if err != nil {
+		return err
+	}
+
+	err = publish()
+	cb.record(generation, err)
+
+	return err
+}
+
+// allow returns ErrCircuitOpen unless a publish may be attempted, and the generation of the
+// state the publish is attempted in.
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	bc, err := collectors.GetNatsCircuitBreakerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == collectors.NatsCircuitBreakerOpen && time.Since(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.transition(bc, collectors.NatsCircuitBreakerHalfOpen)
+	}
+
+	switch cb.state {
+	case collectors.NatsCircuitBreakerOpen:
+		bc.IncCircuitBreakerRejectedCount(cb.name)
+		return 0, ErrCircuitOpen
+	case collectors.NatsCircuitBreakerHalfOpen:
+		if cb.halfOpenInFlight+cb.halfOpenSuccesses >= cb.config.HalfOpenRequests {
+			bc.IncCircuitBreakerRejectedCount(cb.name)
+			return 0, ErrCircuitOpen
+		}
+		cb.halfOpenInFlight++
+	}
+
+	return cb.generation, nil
+}
+
+// record records the outcome of a publish let through by allow. Outcomes of publishes attempted
+// before the last state transition are ignored, and errors that are not failures are neutral.
+func (cb *CircuitBreaker) record(generation uint64, err error) {
+	bc, bcErr := collectors.GetNatsCircuitBreakerMetricsCollector()
+	if bcErr != nil {
+		panic(bcErr)
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+	failed := err != nil && cb.config.IsFailure(err)
+
+	switch cb.state {
+	case collectors.NatsCircuitBreakerClosed:
+		if err == nil {
+			cb.failures = 0
+			return
+		}
+		if !failed {
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.config.FailureThreshold {
+			cb.transition(bc, collectors.NatsCircuitBreakerOpen)
+		}
+	case collectors.NatsCircuitBreakerHalfOpen:
+		cb.halfOpenInFlight--
+		if failed {
+			cb.transition(bc, collectors.NatsCircuitBreakerOpen)
+			return
+		}
+		if err != nil {
+			return
+		}
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.config.HalfOpenRequests {
+			cb.transition(bc, collectors.NatsCircuitBreakerClosed)
+		}
+	}
+}
+
+// transition must be called with the mutex held.
+func (cb *CircuitBreaker) transition(bc collectors.CircuitBreakerMetricsCollector, state string) {
+	bc.IncCircuitBreakerTransitionCount(cb.name, cb.state, state)
+	bc.SetCircuitBreakerState(cb.name, state)
+
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.halfOpenInFlight = 0
+	cb.halfOpenSuccesses = 0
+	if state == collectors.NatsCircuitBreakerOpen {
+		cb.openedAt = time.Now()
+	}
+}
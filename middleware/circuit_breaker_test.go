@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"testing"
+	"time"
+)
+
+func newTestCircuitBreaker(t *testing.T, config CircuitBreakerConfig) *CircuitBreaker {
+	t.Helper()
+	newTestCollectors()
+
+	return NewCircuitBreaker(t.Name(), config)
+}
+
+// expireOpenTimeout lets the next publish through to the half-open state.
+func expireOpenTimeout(cb *CircuitBreaker) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.openedAt = time.Now().Add(-cb.config.OpenTimeout)
+}
+
+func mustAllow(t *testing.T, cb *CircuitBreaker) uint64 {
+	t.Helper()
+
+	generation, err := cb.allow()
+	if err != nil {
+		t.Fatalf("allow() error = %v in state %s", err, cb.State())
+	}
+
+	return generation
+}
+
+func assertState(t *testing.T, cb *CircuitBreaker, want string) {
+	t.Helper()
+
+	if got := cb.State(); got != want {
+		t.Fatalf("State() = %s, want %s", got, want)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 2, HalfOpenRequests: 2})
+
+	cb.record(mustAllow(t, cb), nats.ErrTimeout)
+	assertState(t, cb, collectors.NatsCircuitBreakerClosed)
+	cb.record(mustAllow(t, cb), nats.ErrConnectionClosed)
+	assertState(t, cb, collectors.NatsCircuitBreakerOpen)
+
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v while open, want ErrCircuitOpen", err)
+	}
+
+	expireOpenTimeout(cb)
+	first := mustAllow(t, cb)
+	assertState(t, cb, collectors.NatsCircuitBreakerHalfOpen)
+	second := mustAllow(t, cb)
+
+	cb.record(first, nil)
+	assertState(t, cb, collectors.NatsCircuitBreakerHalfOpen)
+	cb.record(second, nil)
+	assertState(t, cb, collectors.NatsCircuitBreakerClosed)
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1})
+
+	cb.record(mustAllow(t, cb), nats.ErrTimeout)
+	expireOpenTimeout(cb)
+	cb.record(mustAllow(t, cb), nats.ErrNoResponders)
+
+	assertState(t, cb, collectors.NatsCircuitBreakerOpen)
+}
+
+func TestCircuitBreakerHalfOpenAdmission(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1, HalfOpenRequests: 1})
+
+	cb.record(mustAllow(t, cb), nats.ErrTimeout)
+	expireOpenTimeout(cb)
+
+	trial := mustAllow(t, cb)
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v with a trial in flight, want ErrCircuitOpen", err)
+	}
+
+	// A trial failing with an error that is not a failure frees its slot
+	cb.record(trial, nats.ErrMaxPayload)
+	assertState(t, cb, collectors.NatsCircuitBreakerHalfOpen)
+	cb.record(mustAllow(t, cb), nil)
+	assertState(t, cb, collectors.NatsCircuitBreakerClosed)
+}
+
+func TestCircuitBreakerIgnoresStaleGenerations(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 1})
+
+	stale := mustAllow(t, cb)
+	cb.record(mustAllow(t, cb), nats.ErrTimeout)
+	assertState(t, cb, collectors.NatsCircuitBreakerOpen)
+
+	expireOpenTimeout(cb)
+	trial := mustAllow(t, cb)
+
+	// The outcome of a publish let through while closed does not close the circuit
+	cb.record(stale, nil)
+	assertState(t, cb, collectors.NatsCircuitBreakerHalfOpen)
+	if _, err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want ErrCircuitOpen while the trial is in flight", err)
+	}
+
+	cb.record(trial, nil)
+	assertState(t, cb, collectors.NatsCircuitBreakerClosed)
+}
+
+func TestCircuitBreakerFailureClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		wantOpen bool
+	}{
+		{"timeouts", []error{nats.ErrTimeout, nats.ErrTimeout, nats.ErrTimeout}, true},
+		{"connection errors", []error{nats.ErrConnectionClosed, nats.ErrConnectionDraining, nats.ErrReconnectBufExceeded}, true},
+		{"non-failures do not reset the count", []error{nats.ErrTimeout, nats.ErrMaxPayload, nats.ErrTimeout, nats.ErrTimeout}, true},
+		{"success resets the count", []error{nats.ErrTimeout, nats.ErrTimeout, nil, nats.ErrTimeout}, false},
+		{"non-failures", []error{nats.ErrMaxPayload, nats.ErrMaxPayload, nats.ErrMaxPayload}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := newTestCircuitBreaker(t, CircuitBreakerConfig{FailureThreshold: 3})
+			for _, err := range tt.errs {
+				cb.record(mustAllow(t, cb), err)
+			}
+
+			if open := cb.State() == collectors.NatsCircuitBreakerOpen; open != tt.wantOpen {
+				t.Errorf("State() = %s, want open %v", cb.State(), tt.wantOpen)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCustomClassifier(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		FailureThreshold: 1,
+		IsFailure: func(err error) bool {
+			return errors.Is(err, nats.ErrMaxPayload)
+		},
+	})
+
+	cb.record(mustAllow(t, cb), nats.ErrTimeout)
+	assertState(t, cb, collectors.NatsCircuitBreakerClosed)
+	cb.record(mustAllow(t, cb), nats.ErrMaxPayload)
+	assertState(t, cb, collectors.NatsCircuitBreakerOpen)
+}
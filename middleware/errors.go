@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+)
+
+func publishErrorReason(err error) string {
+	var apiErr *nats.APIError
+
+	switch {
+	case errors.Is(err, nats.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return collectors.NatsPublishErrorTimeout
+	case errors.Is(err, nats.ErrNoResponders), errors.Is(err, nats.ErrNoStreamResponse):
+		// js.PublishMsg reports no responders as ErrNoStreamResponse
+		return collectors.NatsPublishErrorNoResponders
+	case errors.Is(err, nats.ErrStreamNotFound),
+		errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamNotFound:
+		return collectors.NatsPublishErrorStreamNotFound
+	case errors.Is(err, nats.ErrConnectionClosed), errors.Is(err, nats.ErrConnectionDraining):
+		return collectors.NatsPublishErrorConnectionClosed
+	case errors.Is(err, nats.ErrMaxPayload):
+		return collectors.NatsPublishErrorMaxPayload
+	case errors.Is(err, context.Canceled):
+		return collectors.NatsPublishErrorCanceled
+	default:
+		return collectors.NatsPublishErrorOther
+	}
+}
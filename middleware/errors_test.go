@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"testing"
+)
+
+func TestPublishErrorReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"timeout", nats.ErrTimeout, collectors.NatsPublishErrorTimeout},
+		{"context deadline", context.DeadlineExceeded, collectors.NatsPublishErrorTimeout},
+		{"no responders", nats.ErrNoResponders, collectors.NatsPublishErrorNoResponders},
+		{"jetstream no responders", nats.ErrNoStreamResponse, collectors.NatsPublishErrorNoResponders},
+		{"wrapped jetstream no responders", fmt.Errorf("publish: %w", nats.ErrNoStreamResponse), collectors.NatsPublishErrorNoResponders},
+		{"stream not found", nats.ErrStreamNotFound, collectors.NatsPublishErrorStreamNotFound},
+		{"stream not found api error", &nats.APIError{Code: 404, ErrorCode: nats.JSErrCodeStreamNotFound, Description: "stream not found"}, collectors.NatsPublishErrorStreamNotFound},
+		{"connection closed", nats.ErrConnectionClosed, collectors.NatsPublishErrorConnectionClosed},
+		{"connection draining", nats.ErrConnectionDraining, collectors.NatsPublishErrorConnectionClosed},
+		{"max payload", nats.ErrMaxPayload, collectors.NatsPublishErrorMaxPayload},
+		{"context canceled", context.Canceled, collectors.NatsPublishErrorCanceled},
+		{"other", errors.New("boom"), collectors.NatsPublishErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := publishErrorReason(tt.err); got != tt.want {
+				t.Errorf("publishErrorReason(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"strconv"
+	"time"
+)
+
+const (
+	// PublishTimestampHeader carries the publish time of a message in Unix nanoseconds.
+	PublishTimestampHeader = "Promnatsfiber-Published-At"
+	// SourceServiceHeader carries the name of the service that published a message.
+	SourceServiceHeader = "Promnatsfiber-Source-Service"
+
+	unknownSourceService = "unknown"
+)
+
+func stampPublishHeaders(msg *nats.Msg, serviceName string) {
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+
+	msg.Header.Set(PublishTimestampHeader, strconv.FormatInt(time.Now().UnixNano(), 10))
+	msg.Header.Set(SourceServiceHeader, serviceName)
+}
+
+// publishHeaders returns the publish time and source service stamped on a message.
+// The boolean is false when the message carries no valid publish timestamp.
+func publishHeaders(msg *nats.Msg) (time.Time, string, bool) {
+	if msg.Header == nil {
+		return time.Time{}, "", false
+	}
+
+	publishedAt, err := strconv.ParseInt(msg.Header.Get(PublishTimestampHeader), 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	sourceService := msg.Header.Get(SourceServiceHeader)
+	if sourceService == "" {
+		sourceService = unknownSourceService
+	}
+
+	return time.Unix(0, publishedAt), sourceService, true
+}
+
+// messageSize returns the size of a message as transmitted to the server, including
+// the encoded headers.
+func messageSize(msg *nats.Msg) int {
+	return len(msg.Data) + headerSize(msg.Header)
+}
+
+// headerSize mirrors the NATS/1.0 header encoding used by nats.go.
+func headerSize(header nats.Header) int {
+	if len(header) == 0 {
+		return 0
+	}
+
+	// "NATS/1.0\r\n" preamble and the trailing "\r\n"
+	size := 12
+	for key, values := range header {
+		for _, value := range values {
+			// "key: value\r\n"
+			size += len(key) + len(value) + 4
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
+)
+
+// processWithInProgressHeartbeats runs the handler while sending in-progress heartbeats, which
+// also stop when the handler panics.
+func processWithInProgressHeartbeats(msg *nats.Msg, subject string, options *handlerOptions, funcToWrap func(*nats.Msg)) {
+	done := make(chan struct{})
+	go sendInProgressHeartbeats(msg, subject, options, done)
+	defer close(done)
+
+	funcToWrap(msg)
+}
+
+// sendInProgressHeartbeats acknowledges a message as in progress every heartbeat interval until
+// done is closed. Once the hard limit is exceeded, the handler is counted and the heartbeats stop.
+func sendInProgressHeartbeats(msg *nats.Msg, subject string, options *handlerOptions, done <-chan struct{}) {
+	hc, err := collectors.GetNatsHandlerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	var heartbeats <-chan time.Time
+	if options.heartbeatInterval > 0 {
+		ticker := time.NewTicker(options.heartbeatInterval)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	var hardLimit <-chan time.Time
+	if options.hardLimit > 0 {
+		timer := time.NewTimer(options.hardLimit)
+		defer timer.Stop()
+		hardLimit = timer.C
+	}
+
+	for {
+		select {
+		case <-heartbeats:
+			if err := msg.InProgress(); err == nil {
+				hc.IncHeartbeatCount(subject)
+			}
+		case <-hardLimit:
+			hc.IncHardLimitExceededCount(subject)
+			return
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWrapProcessJetStreamMessageStopsHeartbeatsOnPanic(t *testing.T) {
+	newTestCollectors()
+
+	handler := WrapProcessJetStreamMessage(func(*nats.Msg) {
+		panic("handler failed")
+	}, WithInProgressHeartbeat(time.Millisecond))
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("handler panic was not propagated")
+				}
+			}()
+			handler(nats.NewMsg("orders.created"))
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines = %d, want at most %d after panicking handlers", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+var testCollectorsOnce sync.Once
+
+// newTestCollectors registers the collectors used by the message handler and publishing wrappers
+// once, since goroutines of earlier tests may still read them.
+func newTestCollectors() {
+	testCollectorsOnce.Do(func() {
+		reg := prometheus.NewRegistry()
+		collectors.NewNatsMetricsCollector(reg, "svc", nil)
+		collectors.NewNatsHandlerMetricsCollector(reg, "svc")
+		collectors.NewNatsActivityCollector(reg, "svc", 0, nil, nil)
+		collectors.NewSubjectNormalizer(nil, false)
+		collectors.NewHeaderLabeler(nil)
+		collectors.NewNatsPublishRetryMetricsCollector(reg, "svc")
+		collectors.NewNatsCircuitBreakerMetricsCollector(reg, "svc")
+	})
+}
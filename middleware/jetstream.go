@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
+)
+
+const unknownConsumerLabel = "unknown"
+
+// WrapProcessJetStreamMsg is the equivalent of WrapProcessJetStreamMessage for handlers of the
+// github.com/nats-io/nats.go/jetstream API.
+func WrapProcessJetStreamMsg(funcToWrap jetstream.MessageHandler) jetstream.MessageHandler {
+	return func(msg jetstream.Msg) {
+		mc, err := collectors.GetNatsMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		hl, err := collectors.GetHeaderLabeler()
+		if err != nil {
+			panic(err)
+		}
+		atc, err := collectors.GetNatsActivityMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.Normalize(msg.Subject())
+		headerLabels := hl.Values(msg.Headers())
+		startTime := time.Now()
+		funcToWrap(msg)
+
+		mc.IncProcessedMessageCount(subject, collectors.NatsJetStreamMessageType, headerLabels...)
+		atc.SetLastProcessedMessageTime(subject, time.Now())
+
+		size := float64(len(msg.Data()) + headerSize(msg.Headers()))
+		mc.ObserveProcessedMessageSize(subject, collectors.NatsJetStreamMessageType, size, headerLabels...)
+		mc.AddProcessedMessageBytes(subject, collectors.NatsJetStreamMessageType, size, headerLabels...)
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		mc.ObserveMessageProcessingDuration(subject, collectors.NatsJetStreamMessageType, elapsed, headerLabels...)
+	}
+}
+
+// Consume instruments consumer.Consume by wrapping the handler with WrapProcessJetStreamMsg.
+func Consume(consumer jetstream.Consumer, funcToWrap jetstream.MessageHandler, opts ...jetstream.PullConsumeOpt) (jetstream.ConsumeContext, error) {
+	return consumer.Consume(WrapProcessJetStreamMsg(funcToWrap), opts...)
+}
+
+type instrumentedMessagesContext struct {
+	jetstream.MessagesContext
+	stream   string
+	consumer string
+}
+
+func (it *instrumentedMessagesContext) Next() (jetstream.Msg, error) {
+	cc, err := collectors.GetNatsJetStreamConsumerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	startTime := time.Now()
+	msg, err := it.MessagesContext.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	cc.ObserveNextWaitDuration(it.stream, it.consumer, elapsed)
+
+	return msg, nil
+}
+
+// Messages instruments consumer.Messages, recording the time spent waiting for each message.
+// Messages returned by the iterator can be processed with WrapProcessJetStreamMsg.
+func Messages(consumer jetstream.Consumer, opts ...jetstream.PullMessagesOpt) (jetstream.MessagesContext, error) {
+	it, err := consumer.Messages(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, name := consumerLabels(consumer)
+
+	return &instrumentedMessagesContext{MessagesContext: it, stream: stream, consumer: name}, nil
+}
+
+type instrumentedMessageBatch struct {
+	msgs chan jetstream.Msg
+	err  error
+}
+
+func (b *instrumentedMessageBatch) Messages() <-chan jetstream.Msg {
+	return b.msgs
+}
+
+// Error must only be called once the Messages channel is closed.
+func (b *instrumentedMessageBatch) Error() error {
+	return b.err
+}
+
+// Fetch instruments consumer.Fetch, recording the batch size, the duration until the batch is
+// complete and empty or failed fetches.
+func Fetch(consumer jetstream.Consumer, batch int, opts ...jetstream.FetchOpt) (jetstream.MessageBatch, error) {
+	startTime := time.Now()
+	messageBatch, err := consumer.Fetch(batch, opts...)
+
+	return instrumentFetch(consumer, startTime, messageBatch, err)
+}
+
+// FetchNoWait instruments consumer.FetchNoWait like Fetch.
+func FetchNoWait(consumer jetstream.Consumer, batch int) (jetstream.MessageBatch, error) {
+	startTime := time.Now()
+	messageBatch, err := consumer.FetchNoWait(batch)
+
+	return instrumentFetch(consumer, startTime, messageBatch, err)
+}
+
+func instrumentFetch(consumer jetstream.Consumer, startTime time.Time, messageBatch jetstream.MessageBatch, err error) (jetstream.MessageBatch, error) {
+	cc, ccErr := collectors.GetNatsJetStreamConsumerMetricsCollector()
+	if ccErr != nil {
+		panic(ccErr)
+	}
+	stream, name := consumerLabels(consumer)
+	if err != nil {
+		cc.IncFetchErrorCount(stream, name)
+		return nil, err
+	}
+
+	instrumentedBatch := &instrumentedMessageBatch{
+		msgs: make(chan jetstream.Msg, cap(messageBatch.Messages())),
+	}
+
+	go func() {
+		received := 0
+		for msg := range messageBatch.Messages() {
+			received++
+			instrumentedBatch.msgs <- msg
+		}
+		instrumentedBatch.err = messageBatch.Error()
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		cc.ObserveFetchDuration(stream, name, elapsed)
+		cc.ObserveFetchBatchSize(stream, name, float64(received))
+		if received == 0 {
+			cc.IncEmptyFetchCount(stream, name)
+		}
+		if instrumentedBatch.err != nil {
+			cc.IncFetchErrorCount(stream, name)
+		}
+
+		close(instrumentedBatch.msgs)
+	}()
+
+	return instrumentedBatch, nil
+}
+
+func consumerLabels(consumer jetstream.Consumer) (string, string) {
+	info := consumer.CachedInfo()
+	if info == nil {
+		return unknownConsumerLabel, unknownConsumerLabel
+	}
+
+	return info.Stream, info.Name
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/nats-io/nats.go"
 	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"strconv"
 	"time"
 )
 
@@ -12,70 +13,239 @@ func WrapProcessMessage(funcToWrap func(*nats.Msg)) func(*nats.Msg) {
 		if err != nil {
 			panic(err)
 		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		hl, err := collectors.GetHeaderLabeler()
+		if err != nil {
+			panic(err)
+		}
+		atc, err := collectors.GetNatsActivityMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.MessageSubject(msg)
+		headerLabels := hl.Values(msg.Header)
 		startTime := time.Now()
+
+		if publishedAt, sourceService, ok := publishHeaders(msg); ok {
+			latency := startTime.Sub(publishedAt).Seconds()
+			if latency < 0 {
+				// Publisher and subscriber clocks are skewed
+				latency = 0
+			}
+			mc.ObserveMessageEndToEndLatency(subject, sourceService, latency)
+		}
+
 		funcToWrap(msg)
 
-		mc.IncProcessedMessageCount(msg.Subject, collectors.NatsSimpleMessageType)
+		mc.IncProcessedMessageCount(subject, collectors.NatsSimpleMessageType, headerLabels...)
+		atc.SetLastProcessedMessageTime(subject, time.Now())
+
+		size := float64(messageSize(msg))
+		mc.ObserveProcessedMessageSize(subject, collectors.NatsSimpleMessageType, size, headerLabels...)
+		mc.AddProcessedMessageBytes(subject, collectors.NatsSimpleMessageType, size, headerLabels...)
 
 		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
-		mc.ObserveMessageProcessingDuration(msg.Subject, collectors.NatsSimpleMessageType, elapsed)
+		mc.ObserveMessageProcessingDuration(subject, collectors.NatsSimpleMessageType, elapsed, headerLabels...)
 	}
 }
 
-func WrapProcessJetStreamMessage(funcToWrap func(*nats.Msg)) func(*nats.Msg) {
+// WrapProcessJetStreamMessage instruments a JetStream message handler. With
+// WithInProgressHeartbeat, messages are acknowledged as in progress while the handler runs.
+func WrapProcessJetStreamMessage(funcToWrap func(*nats.Msg), opts ...HandlerOption) func(*nats.Msg) {
+	options := newHandlerOptions(opts)
+
 	return func(msg *nats.Msg) {
 		mc, err := collectors.GetNatsMetricsCollector()
 		if err != nil {
 			panic(err)
 		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		hl, err := collectors.GetHeaderLabeler()
+		if err != nil {
+			panic(err)
+		}
+		atc, err := collectors.GetNatsActivityMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.MessageSubject(msg)
+		headerLabels := hl.Values(msg.Header)
 		startTime := time.Now()
-		funcToWrap(msg)
 
-		mc.IncProcessedMessageCount(msg.Subject, collectors.NatsJetStreamMessageType)
+		if options.heartbeatInterval > 0 || options.hardLimit > 0 {
+			processWithInProgressHeartbeats(msg, subject, options, funcToWrap)
+		} else {
+			funcToWrap(msg)
+		}
+
+		mc.IncProcessedMessageCount(subject, collectors.NatsJetStreamMessageType, headerLabels...)
+		atc.SetLastProcessedMessageTime(subject, time.Now())
+
+		size := float64(messageSize(msg))
+		mc.ObserveProcessedMessageSize(subject, collectors.NatsJetStreamMessageType, size, headerLabels...)
+		mc.AddProcessedMessageBytes(subject, collectors.NatsJetStreamMessageType, size, headerLabels...)
 
 		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
-		mc.ObserveMessageProcessingDuration(msg.Subject, collectors.NatsJetStreamMessageType, elapsed)
+		mc.ObserveMessageProcessingDuration(subject, collectors.NatsJetStreamMessageType, elapsed, headerLabels...)
 	}
 }
 
-func WrapPublishMessage(nc *nats.Conn) func(string, []byte) error {
+func WrapPublishMessage(nc *nats.Conn, opts ...PublishOption) func(string, []byte) error {
+	options := newPublishOptions(opts)
+
 	return func(subject string, data []byte) error {
-		mc, err := collectors.GetNatsMetricsCollector()
-		if err != nil {
-			panic(err)
-		}
-		startTime := time.Now()
-		err = nc.Publish(subject, data)
-		if err != nil {
-			return err
-		}
+		msg := nats.NewMsg(subject)
+		msg.Data = data
 
-		mc.IncPublishedMessageCount(subject, collectors.NatsSimpleMessageType)
+		return options.breaker.guard(func() error {
+			return publishMsg(nc, msg, options)
+		})
+	}
+}
 
-		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
-		mc.ObserveMessagePublishingDuration(subject, collectors.NatsSimpleMessageType, elapsed)
+// WrapPublishMsg instruments nc.PublishMsg, allowing headers and a reply subject to be sent.
+func WrapPublishMsg(nc *nats.Conn, opts ...PublishOption) func(*nats.Msg) error {
+	options := newPublishOptions(opts)
+
+	return func(msg *nats.Msg) error {
+		return options.breaker.guard(func() error {
+			return publishMsg(nc, msg, options)
+		})
+	}
+}
+
+// WrapPublishRequest instruments nc.PublishRequest.
+func WrapPublishRequest(nc *nats.Conn, opts ...PublishOption) func(string, string, []byte) error {
+	options := newPublishOptions(opts)
 
-		return nil
+	return func(subject, reply string, data []byte) error {
+		msg := nats.NewMsg(subject)
+		msg.Reply = reply
+		msg.Data = data
+
+		return options.breaker.guard(func() error {
+			return publishMsg(nc, msg, options)
+		})
 	}
 }
 
-func WrapPublishJetStreamMessage(js nats.JetStreamContext) func(string, []byte) error {
+func WrapPublishJetStreamMessage(js nats.JetStreamContext, opts ...PublishOption) func(string, []byte) error {
+	options := newPublishOptions(opts)
+
 	return func(subject string, data []byte) error {
-		mc, err := collectors.GetNatsMetricsCollector()
-		if err != nil {
-			panic(err)
-		}
-		startTime := time.Now()
-		_, err = js.Publish(subject, data)
-		if err != nil {
+		msg := nats.NewMsg(subject)
+		msg.Data = data
+
+		return options.breaker.guard(func() error {
+			_, err := publishJetStreamMsgWithRetry(js, msg, options)
 			return err
-		}
+		})
+	}
+}
 
-		mc.IncPublishedMessageCount(subject, collectors.NatsJetStreamMessageType)
+// WrapPublishJetStreamMsg instruments js.PublishMsg, passing through the publish options such
+// as nats.MsgId, nats.ExpectStream or nats.ExpectLastSequence, and records the returned PubAck.
+// Failed publishes are retried when configured with WithRetryPolicy.
+func WrapPublishJetStreamMsg(js nats.JetStreamContext, opts ...PublishOption) func(*nats.Msg, ...nats.PubOpt) (*nats.PubAck, error) {
+	options := newPublishOptions(opts)
 
-		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
-		mc.ObserveMessagePublishingDuration(subject, collectors.NatsJetStreamMessageType, elapsed)
+	return func(msg *nats.Msg, pubOpts ...nats.PubOpt) (*nats.PubAck, error) {
+		var ack *nats.PubAck
+		err := options.breaker.guard(func() error {
+			var err error
+			ack, err = publishJetStreamMsgWithRetry(js, msg, options, pubOpts...)
+			return err
+		})
+
+		return ack, err
+	}
+}
 
-		return nil
+func publishMsg(nc *nats.Conn, msg *nats.Msg, options *publishOptions) error {
+	mc, err := collectors.GetNatsMetricsCollector()
+	if err != nil {
+		panic(err)
 	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	hl, err := collectors.GetHeaderLabeler()
+	if err != nil {
+		panic(err)
+	}
+	atc, err := collectors.GetNatsActivityMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	if options.stampHeaders {
+		stampPublishHeaders(msg, mc.GetServiceName())
+	}
+
+	subjectLabel := sn.Normalize(msg.Subject)
+	headerLabels := hl.Values(msg.Header)
+	startTime := time.Now()
+	err = nc.PublishMsg(msg)
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	mc.ObserveMessagePublishingDuration(subjectLabel, collectors.NatsSimpleMessageType, elapsed, headerLabels...)
+	if err != nil {
+		mc.IncPublishErrorCount(subjectLabel, collectors.NatsSimpleMessageType, publishErrorReason(err))
+		return err
+	}
+
+	mc.IncPublishedMessageCount(subjectLabel, collectors.NatsSimpleMessageType, headerLabels...)
+	atc.SetLastPublishedMessageTime(subjectLabel, time.Now())
+
+	size := float64(messageSize(msg))
+	mc.ObservePublishedMessageSize(subjectLabel, collectors.NatsSimpleMessageType, size, headerLabels...)
+	mc.AddPublishedMessageBytes(subjectLabel, collectors.NatsSimpleMessageType, size, headerLabels...)
+
+	return nil
+}
+
+func publishJetStreamMsg(js nats.JetStreamContext, msg *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	mc, err := collectors.GetNatsMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	hl, err := collectors.GetHeaderLabeler()
+	if err != nil {
+		panic(err)
+	}
+	atc, err := collectors.GetNatsActivityMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	subjectLabel := sn.Normalize(msg.Subject)
+	headerLabels := hl.Values(msg.Header)
+	startTime := time.Now()
+	ack, err := js.PublishMsg(msg, opts...)
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	mc.ObserveMessagePublishingDuration(subjectLabel, collectors.NatsJetStreamMessageType, elapsed, headerLabels...)
+	if err != nil {
+		mc.IncPublishErrorCount(subjectLabel, collectors.NatsJetStreamMessageType, publishErrorReason(err))
+		return nil, err
+	}
+
+	mc.IncPublishedMessageCount(subjectLabel, collectors.NatsJetStreamMessageType, headerLabels...)
+	atc.SetLastPublishedMessageTime(subjectLabel, time.Now())
+
+	size := float64(messageSize(msg))
+	mc.ObservePublishedMessageSize(subjectLabel, collectors.NatsJetStreamMessageType, size, headerLabels...)
+	mc.AddPublishedMessageBytes(subjectLabel, collectors.NatsJetStreamMessageType, size, headerLabels...)
+
+	mc.IncPubAckCount(ack.Stream, strconv.FormatBool(ack.Duplicate))
+	mc.SetPubAckSequence(ack.Stream, float64(ack.Sequence))
+
+	return ack, nil
 }
@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// asyncAckObserverWindow is the number of oldest pending futures an asyncAckObserver waits on at
+// once. Later futures are picked up as soon as older ones resolve.
+const asyncAckObserverWindow = 32
+
+// instrumentedPubAckFuture forwards the outcome of the wrapped future once it has been recorded,
+// since the acknowledgement channels of a nats.PubAckFuture can only be received from once.
+type instrumentedPubAckFuture struct {
+	msg *nats.Msg
+	ok  chan *nats.PubAck
+	err chan error
+}
+
+func (f *instrumentedPubAckFuture) Ok() <-chan *nats.PubAck {
+	return f.ok
+}
+
+func (f *instrumentedPubAckFuture) Err() <-chan error {
+	return f.err
+}
+
+func (f *instrumentedPubAckFuture) Msg() *nats.Msg {
+	return f.msg
+}
+
+// pendingAck is a published message whose acknowledgement is awaited by an asyncAckObserver.
+type pendingAck struct {
+	future   nats.PubAckFuture
+	deadline time.Time
+	resolve  func(ack *nats.PubAck, err error)
+}
+
+// asyncAckObserver waits on the futures of asynchronous publishes from a single goroutine, which
+// runs while publishes are pending, and resolves them in the order of their outcomes or with
+// nats.ErrTimeout once their ack timeout expires.
+type asyncAckObserver struct {
+	ackTimeout time.Duration
+
+	mu      sync.Mutex
+	pending []*pendingAck
+	running bool
+	added   chan struct{}
+}
+
+func newAsyncAckObserver(ackTimeout time.Duration) *asyncAckObserver {
+	return &asyncAckObserver{
+		ackTimeout: ackTimeout,
+		added:      make(chan struct{}, 1),
+	}
+}
+
+func (o *asyncAckObserver) observe(future nats.PubAckFuture, startTime time.Time, resolve func(ack *nats.PubAck, err error)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pending = append(o.pending, &pendingAck{
+		future:   future,
+		deadline: startTime.Add(o.ackTimeout),
+		resolve:  resolve,
+	})
+	if !o.running {
+		o.running = true
+		go o.run()
+		return
+	}
+
+	select {
+	case o.added <- struct{}{}:
+	default:
+	}
+}
+
+func (o *asyncAckObserver) run() {
+	timer := time.NewTimer(o.ackTimeout)
+	defer timer.Stop()
+
+	for {
+		o.mu.Lock()
+		if len(o.pending) == 0 {
+			o.running = false
+			o.mu.Unlock()
+			return
+		}
+		window := append([]*pendingAck(nil), o.pending[:min(len(o.pending), asyncAckObserverWindow)]...)
+		o.mu.Unlock()
+
+		// Pending futures share the ack timeout, so the oldest one expires first
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(window[0].deadline))
+
+		cases := make([]reflect.SelectCase, 0, 2*len(window)+2)
+		for _, p := range window {
+			cases = append(cases,
+				reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.future.Ok())},
+				reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.future.Err())},
+			)
+		}
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(o.added)},
+		)
+
+		chosen, value, _ := reflect.Select(cases)
+		switch {
+		case chosen < 2*len(window):
+			p := window[chosen/2]
+			o.remove(p)
+			if chosen%2 == 0 {
+				p.resolve(value.Interface().(*nats.PubAck), nil)
+			} else {
+				p.resolve(nil, value.Interface().(error))
+			}
+		case chosen == 2*len(window):
+			for _, p := range o.expire(time.Now()) {
+				p.resolve(nil, nats.ErrTimeout)
+			}
+		}
+	}
+}
+
+func (o *asyncAckObserver) remove(p *pendingAck) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, pending := range o.pending {
+		if pending == p {
+			o.pending = append(o.pending[:i], o.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *asyncAckObserver) expire(now time.Time) []*pendingAck {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for n < len(o.pending) && !o.pending[n].deadline.After(now) {
+		n++
+	}
+	expired := append([]*pendingAck(nil), o.pending[:n]...)
+	o.pending = o.pending[n:]
+
+	return expired
+}
+
+func WrapPublishJetStreamMessageAsync(js nats.JetStreamContext, opts ...PublishOption) func(string, []byte) (nats.PubAckFuture, error) {
+	options := newPublishOptions(opts)
+	observer := newAsyncAckObserver(options.ackTimeout)
+
+	return func(subject string, data []byte) (nats.PubAckFuture, error) {
+		msg := nats.NewMsg(subject)
+		msg.Data = data
+
+		return publishJetStreamMsgAsync(js, observer, msg, options)
+	}
+}
+
+// WrapPublishJetStreamMsgAsync instruments js.PublishMsgAsync. The returned future resolves once
+// the acknowledgement or error has been recorded, or with nats.ErrTimeout when no acknowledgement
+// arrives within the ack timeout configured with WithAckTimeout.
+func WrapPublishJetStreamMsgAsync(js nats.JetStreamContext, opts ...PublishOption) func(*nats.Msg, ...nats.PubOpt) (nats.PubAckFuture, error) {
+	options := newPublishOptions(opts)
+	observer := newAsyncAckObserver(options.ackTimeout)
+
+	return func(msg *nats.Msg, pubOpts ...nats.PubOpt) (nats.PubAckFuture, error) {
+		return publishJetStreamMsgAsync(js, observer, msg, options, pubOpts...)
+	}
+}
+
+func publishJetStreamMsgAsync(js nats.JetStreamContext, observer *asyncAckObserver, msg *nats.Msg, options *publishOptions, pubOpts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	mc, err := collectors.GetNatsMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	ac, err := collectors.GetNatsAsyncPublishMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	hl, err := collectors.GetHeaderLabeler()
+	if err != nil {
+		panic(err)
+	}
+	atc, err := collectors.GetNatsActivityMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	var generation uint64
+	if options.breaker != nil {
+		if generation, err = options.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	recordOutcome := func(err error) {
+		if options.breaker != nil {
+			options.breaker.record(generation, err)
+		}
+	}
+
+	subjectLabel := sn.Normalize(msg.Subject)
+	headerLabels := hl.Values(msg.Header)
+	startTime := time.Now()
+	future, err := js.PublishMsgAsync(msg, pubOpts...)
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	ac.ObserveAsyncPublishCallDuration(elapsed)
+	ac.SetAsyncPublishPending(float64(js.PublishAsyncPending()))
+	if err != nil {
+		reason := publishErrorReason(err)
+		ac.IncAsyncPublishErrorCount(subjectLabel, reason)
+		mc.IncPublishErrorCount(subjectLabel, collectors.NatsJetStreamMessageType, reason)
+		recordOutcome(err)
+		return nil, err
+	}
+
+	instrumentedFuture := &instrumentedPubAckFuture{
+		msg: future.Msg(),
+		ok:  make(chan *nats.PubAck, 1),
+		err: make(chan error, 1),
+	}
+
+	observer.observe(future, startTime, func(ack *nats.PubAck, err error) {
+		if err != nil {
+			observeAsyncPublishError(mc, ac, msg, subjectLabel, startTime, err)
+			recordOutcome(err)
+			instrumentedFuture.err <- err
+			ac.SetAsyncPublishPending(float64(js.PublishAsyncPending()))
+			return
+		}
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		ac.ObserveAsyncPublishAckDuration(ack.Stream, collectors.NatsAsyncPublishOutcomeAck, elapsed)
+		ac.IncAsyncPublishAckCount(ack.Stream)
+		if ack.Duplicate {
+			ac.IncAsyncPublishDuplicateCount(ack.Stream)
+		}
+
+		mc.IncPublishedMessageCount(subjectLabel, collectors.NatsJetStreamMessageType, headerLabels...)
+		atc.SetLastPublishedMessageTime(subjectLabel, time.Now())
+
+		size := float64(messageSize(msg))
+		mc.ObservePublishedMessageSize(subjectLabel, collectors.NatsJetStreamMessageType, size, headerLabels...)
+		mc.AddPublishedMessageBytes(subjectLabel, collectors.NatsJetStreamMessageType, size, headerLabels...)
+
+		recordOutcome(nil)
+		instrumentedFuture.ok <- ack
+		ac.SetAsyncPublishPending(float64(js.PublishAsyncPending()))
+	})
+
+	return instrumentedFuture, nil
+}
+
+func observeAsyncPublishError(mc collectors.AsyncMessageBrokerMetricsCollector, ac collectors.AsyncPublishMetricsCollector, msg *nats.Msg, subject string, startTime time.Time, err error) {
+	// The stream is only known for publishes expecting a specific stream
+	stream := msg.Header.Get(nats.ExpectedStreamHdr)
+	if stream == "" {
+		stream = collectors.NatsUnknownStream
+	}
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	ac.ObserveAsyncPublishAckDuration(stream, collectors.NatsAsyncPublishOutcomeError, elapsed)
+
+	reason := publishErrorReason(err)
+	ac.IncAsyncPublishErrorCount(subject, reason)
+	mc.IncPublishErrorCount(subject, collectors.NatsJetStreamMessageType, reason)
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
+)
+
+// BatchPublishResult holds the outcome of every message of a published batch, in the order of
+// the messages.
+type BatchPublishResult struct {
+	// Acks holds the acknowledgements of the messages, which are nil for failed messages.
+	Acks []*nats.PubAck
+	// Errors holds the errors of the failed messages, which are nil for acknowledged messages.
+	Errors []error
+	// Failed is the number of failed messages.
+	Failed int
+}
+
+// Err returns the errors of the failed messages joined together, or nil when all messages were
+// acknowledged.
+func (r *BatchPublishResult) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+
+	var errs []error
+	for i, err := range r.Errors {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("message %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WrapPublishJetStreamBatch publishes batches of messages asynchronously like
+// WrapPublishJetStreamMsgAsync and waits until all of them are acknowledged or the context is
+// done. Messages not published or acknowledged before the context is done fail with the error of
+// the context. The result reports the outcome of every message, and the returned error is the
+// one of BatchPublishResult.Err. The publish options are passed for every message, so message
+// IDs are set through the nats.MsgIdHdr header instead of nats.MsgId.
+func WrapPublishJetStreamBatch(js nats.JetStreamContext, opts ...PublishOption) func(context.Context, []*nats.Msg, ...nats.PubOpt) (*BatchPublishResult, error) {
+	options := newPublishOptions(opts)
+	observer := newAsyncAckObserver(options.ackTimeout)
+
+	return func(ctx context.Context, msgs []*nats.Msg, pubOpts ...nats.PubOpt) (*BatchPublishResult, error) {
+		bc, err := collectors.GetNatsBatchPublishMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		startTime := time.Now()
+		bc.ObserveBatchPublishSize(float64(len(msgs)))
+
+		result := &BatchPublishResult{
+			Acks:   make([]*nats.PubAck, len(msgs)),
+			Errors: make([]error, len(msgs)),
+		}
+
+		futures := make([]nats.PubAckFuture, len(msgs))
+		for i, msg := range msgs {
+			if ctx.Err() != nil {
+				result.Errors[i] = ctx.Err()
+				continue
+			}
+
+			futures[i], result.Errors[i] = publishJetStreamMsgAsync(js, observer, msg, options, pubOpts...)
+		}
+
+		for i, future := range futures {
+			if future == nil {
+				continue
+			}
+
+			result.Acks[i], result.Errors[i] = awaitPubAck(ctx, future)
+		}
+
+		for _, err := range result.Errors {
+			if err != nil {
+				result.Failed++
+				bc.IncBatchPublishFailedMessageCount(publishErrorReason(err))
+			}
+		}
+
+		outcome := collectors.NatsBatchPublishOutcomeComplete
+		switch {
+		case result.Failed == len(msgs) && len(msgs) > 0:
+			outcome = collectors.NatsBatchPublishOutcomeFailed
+		case result.Failed > 0:
+			outcome = collectors.NatsBatchPublishOutcomePartial
+		}
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		bc.ObserveBatchPublishDuration(outcome, elapsed)
+
+		return result, result.Err()
+	}
+}
+
+// awaitPubAck waits for the outcome of the future until the context is done. Outcomes already
+// available once the context is done take precedence over the error of the context.
+func awaitPubAck(ctx context.Context, future nats.PubAckFuture) (*nats.PubAck, error) {
+	select {
+	case ack := <-future.Ok():
+		return ack, nil
+	case err := <-future.Err():
+		return nil, err
+	case <-ctx.Done():
+	}
+
+	select {
+	case ack := <-future.Ok():
+		return ack, nil
+	case err := <-future.Err():
+		return nil, err
+	default:
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"sync"
+	"time"
+)
+
+// ContextHandler processes a message within a context that is cancelled on shutdown or once the
+// handler deadline is exceeded.
+type ContextHandler func(ctx context.Context, msg *nats.Msg) error
+
+// WrapProcessMessageWithContext is the equivalent of WrapProcessMessage for context-aware
+// handlers. The context passed to the handler is derived from ctx, which is expected to be
+// cancelled on shutdown, and only has a deadline when configured with WithHandlerTimeout.
+func WrapProcessMessageWithContext(ctx context.Context, funcToWrap ContextHandler, opts ...HandlerOption) func(*nats.Msg) {
+	options := newHandlerOptions(opts)
+
+	return WrapProcessMessage(func(msg *nats.Msg) {
+		processWithContext(ctx, msg, collectors.NatsSimpleMessageType, options.timeout, funcToWrap)
+	})
+}
+
+// WrapProcessJetStreamMessageWithContext is the equivalent of WrapProcessJetStreamMessage for
+// context-aware handlers. The deadline of the context passed to the handler is the AckWait of
+// the consumer, looked up once per subscription, unless configured with WithHandlerTimeout.
+// In-progress heartbeats are configured like for WrapProcessJetStreamMessage. Since heartbeats
+// keep the message alive past AckWait, the deadline of heartbeated handlers is the hard limit
+// instead, if any.
+func WrapProcessJetStreamMessageWithContext(ctx context.Context, funcToWrap ContextHandler, opts ...HandlerOption) func(*nats.Msg) {
+	return wrapProcessJetStreamMessageWithContext(ctx, funcToWrap, newAckWaitCache(consumerAckWait), opts...)
+}
+
+func wrapProcessJetStreamMessageWithContext(ctx context.Context, funcToWrap ContextHandler, ackWaits *ackWaitCache, opts ...HandlerOption) func(*nats.Msg) {
+	options := newHandlerOptions(opts)
+
+	return WrapProcessJetStreamMessage(func(msg *nats.Msg) {
+		timeout := options.timeout
+		if timeout <= 0 {
+			if options.heartbeatInterval > 0 {
+				timeout = options.hardLimit
+			} else {
+				timeout = ackWaits.get(msg.Sub)
+			}
+		}
+
+		processWithContext(ctx, msg, collectors.NatsJetStreamMessageType, timeout, funcToWrap)
+	}, opts...)
+}
+
+func processWithContext(ctx context.Context, msg *nats.Msg, messageType string, timeout time.Duration, funcToWrap ContextHandler) {
+	hc, err := collectors.GetNatsHandlerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+
+	var handlerCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		handlerCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		handlerCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	err = funcToWrap(handlerCtx, msg)
+
+	subject := sn.MessageSubject(msg)
+	if err != nil {
+		hc.IncHandlerErrorCount(subject, messageType)
+	}
+	if errors.Is(handlerCtx.Err(), context.DeadlineExceeded) {
+		hc.IncHandlerTimeoutCount(subject, messageType)
+	}
+}
+
+// ackWaitRetryInterval is how long a failed AckWait lookup is cached before it is retried.
+// Meanwhile, handlers run without a deadline.
+const ackWaitRetryInterval = 5 * time.Second
+
+// ackWaitCache holds the AckWait of the consumers of JetStream subscriptions. Concurrent lookups
+// for a subscription share a single consumer info request, which is made without holding the
+// cache lock. Entries of subscriptions that are no longer valid are evicted on the next lookup.
+type ackWaitCache struct {
+	lookup func(*nats.Subscription) (time.Duration, error)
+
+	mu      sync.Mutex
+	entries map[*nats.Subscription]*ackWaitEntry
+}
+
+func newAckWaitCache(lookup func(*nats.Subscription) (time.Duration, error)) *ackWaitCache {
+	return &ackWaitCache{lookup: lookup, entries: make(map[*nats.Subscription]*ackWaitEntry)}
+}
+
+func consumerAckWait(sub *nats.Subscription) (time.Duration, error) {
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Config.AckWait, nil
+}
+
+type ackWaitEntry struct {
+	// ready is closed once the lookup completed
+	ready   chan struct{}
+	ackWait time.Duration
+	// expires is set for failed lookups, which are retried afterwards
+	expires time.Time
+}
+
+func (c *ackWaitCache) get(sub *nats.Subscription) time.Duration {
+	if sub == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.entries[sub]; ok {
+		select {
+		case <-entry.ready:
+			if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+				c.mu.Unlock()
+				return entry.ackWait
+			}
+		default:
+			c.mu.Unlock()
+			<-entry.ready
+			return entry.ackWait
+		}
+	}
+
+	for s := range c.entries {
+		if !s.IsValid() {
+			delete(c.entries, s)
+		}
+	}
+	entry := &ackWaitEntry{ready: make(chan struct{})}
+	c.entries[sub] = entry
+	c.mu.Unlock()
+
+	if ackWait, err := c.lookup(sub); err != nil {
+		entry.expires = time.Now().Add(ackWaitRetryInterval)
+	} else {
+		entry.ackWait = ackWait
+	}
+	close(entry.ready)
+
+	return entry.ackWait
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"github.com/nats-io/nats.go"
+	"testing"
+	"time"
+)
+
+func TestWrapProcessJetStreamMessageWithContextDeadline(t *testing.T) {
+	newTestCollectors()
+
+	const ackWait = 10 * time.Millisecond
+
+	tests := []struct {
+		name         string
+		opts         []HandlerOption
+		wantDeadline time.Duration
+	}{
+		{"ack wait", nil, ackWait},
+		{"handler timeout", []HandlerOption{WithHandlerTimeout(time.Hour)}, time.Hour},
+		{"heartbeats without hard limit", []HandlerOption{WithInProgressHeartbeat(time.Millisecond)}, 0},
+		{"heartbeats with hard limit", []HandlerOption{WithInProgressHeartbeat(time.Millisecond), WithHardLimit(time.Minute)}, time.Minute},
+		{"hard limit without heartbeats", []HandlerOption{WithHardLimit(time.Minute)}, ackWait},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ackWaits := newAckWaitCache(func(*nats.Subscription) (time.Duration, error) {
+				return ackWait, nil
+			})
+
+			var deadline time.Time
+			var hasDeadline bool
+			startTime := time.Now()
+			handler := wrapProcessJetStreamMessageWithContext(context.Background(), func(ctx context.Context, msg *nats.Msg) error {
+				deadline, hasDeadline = ctx.Deadline()
+				return nil
+			}, ackWaits, tt.opts...)
+
+			msg := nats.NewMsg("orders.created")
+			msg.Sub = &nats.Subscription{}
+			handler(msg)
+
+			if tt.wantDeadline == 0 {
+				if hasDeadline {
+					t.Errorf("deadline = %v, want none", deadline.Sub(startTime))
+				}
+				return
+			}
+			if !hasDeadline {
+				t.Fatalf("deadline = none, want %v", tt.wantDeadline)
+			}
+			if got := deadline.Sub(startTime); got < tt.wantDeadline || got > tt.wantDeadline+time.Second {
+				t.Errorf("deadline = %v, want %v", got, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestWrapProcessJetStreamMessageWithContextHeartbeatsPastAckWait(t *testing.T) {
+	newTestCollectors()
+
+	ackWaits := newAckWaitCache(func(*nats.Subscription) (time.Duration, error) {
+		return 5 * time.Millisecond, nil
+	})
+
+	var handlerErr error
+	handler := wrapProcessJetStreamMessageWithContext(context.Background(), func(ctx context.Context, msg *nats.Msg) error {
+		select {
+		case <-ctx.Done():
+			handlerErr = ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	}, ackWaits, WithInProgressHeartbeat(time.Millisecond))
+
+	msg := nats.NewMsg("orders.created")
+	msg.Sub = &nats.Subscription{}
+	handler(msg)
+
+	if handlerErr != nil {
+		t.Errorf("handler context error = %v, want none while heartbeats are sent", handlerErr)
+	}
+}
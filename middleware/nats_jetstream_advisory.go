@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+)
+
+const (
+	maxDeliveriesAdvisorySubject = "$JS.EVENT.ADVISORY.CONSUMER.MAX_DELIVERIES.>"
+	terminatedAdvisorySubject    = "$JS.EVENT.ADVISORY.CONSUMER.MSG_TERMINATED.>"
+	nakedAdvisorySubject         = "$JS.EVENT.ADVISORY.CONSUMER.MSG_NAKED.>"
+
+	// DeadLetterAdvisoryHeader carries the advisory type of a message reference republished to
+	// the dead-letter subject.
+	DeadLetterAdvisoryHeader = "Promnatsfiber-Advisory"
+)
+
+// jetStreamAdvisory holds the fields shared by the consumer message advisories. The stream
+// sequence and the deliveries identify the offending message.
+type jetStreamAdvisory struct {
+	Stream     string `json:"stream"`
+	Consumer   string `json:"consumer"`
+	StreamSeq  uint64 `json:"stream_seq"`
+	Deliveries uint64 `json:"deliveries"`
+}
+
+// SubscribeJetStreamAdvisories subscribes to the max deliveries, terminated and naked message
+// advisories of all consumers and counts them by stream and consumer. With WithDeadLetterSubject,
+// the advisories of messages that exceeded their maximum deliveries or were terminated are
+// republished to the dead-letter subject. With WithAdvisoryQueueGroup, the subscriptions join
+// the queue group, so that every advisory is handled by one replica only.
+func SubscribeJetStreamAdvisories(nc *nats.Conn, opts ...AdvisoryOption) ([]*nats.Subscription, error) {
+	options := newAdvisoryOptions(opts)
+
+	advisories := []struct {
+		subject    string
+		advisory   string
+		deadLetter bool
+	}{
+		{maxDeliveriesAdvisorySubject, collectors.NatsMaxDeliveriesAdvisory, true},
+		{terminatedAdvisorySubject, collectors.NatsTerminatedAdvisory, true},
+		{nakedAdvisorySubject, collectors.NatsNakedAdvisory, false},
+	}
+
+	var subs []*nats.Subscription
+	for _, a := range advisories {
+		advisory, deadLetterSubject := a.advisory, ""
+		if a.deadLetter {
+			deadLetterSubject = options.deadLetterSubject
+		}
+
+		sub, err := nc.QueueSubscribe(a.subject, options.queueGroup, func(msg *nats.Msg) {
+			handleJetStreamAdvisory(nc, msg, advisory, deadLetterSubject)
+		})
+		if err != nil {
+			for _, s := range subs {
+				_ = s.Unsubscribe()
+			}
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// handleJetStreamAdvisory counts an advisory and republishes it to the dead-letter subject,
+// unless the subject is empty.
+func handleJetStreamAdvisory(nc *nats.Conn, msg *nats.Msg, advisory, deadLetterSubject string) {
+	ac, err := collectors.GetNatsJetStreamAdvisoryMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	var event jetStreamAdvisory
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		ac.IncAdvisoryCount(collectors.NatsUnknownStream, collectors.NatsUnknownConsumer, collectors.NatsInvalidAdvisory)
+		return
+	}
+
+	ac.IncAdvisoryCount(event.Stream, event.Consumer, advisory)
+
+	if deadLetterSubject == "" {
+		return
+	}
+
+	deadLetterMsg := nats.NewMsg(deadLetterSubject)
+	deadLetterMsg.Header.Set(DeadLetterAdvisoryHeader, advisory)
+	deadLetterMsg.Data = msg.Data
+
+	if err := nc.PublishMsg(deadLetterMsg); err != nil {
+		ac.IncDeadLetterErrorCount(event.Stream, event.Consumer)
+		return
+	}
+	ac.IncDeadLetterCount(event.Stream, event.Consumer)
+}
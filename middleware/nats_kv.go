@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"sync"
+	"time"
+)
+
+type instrumentedKeyValue struct {
+	nats.KeyValue
+}
+
+// WrapKeyValue instruments the Get, Put, Create, Update, Delete and Purge operations and the
+// watchers of a key-value store. Keys are never used as labels.
+func WrapKeyValue(kv nats.KeyValue) nats.KeyValue {
+	return &instrumentedKeyValue{KeyValue: kv}
+}
+
+func (kv *instrumentedKeyValue) Get(key string) (nats.KeyValueEntry, error) {
+	startTime := time.Now()
+	entry, err := kv.KeyValue.Get(key)
+	kv.observeOperation(collectors.NatsKeyValueGetOperation, startTime, err)
+
+	return entry, err
+}
+
+func (kv *instrumentedKeyValue) GetRevision(key string, revision uint64) (nats.KeyValueEntry, error) {
+	startTime := time.Now()
+	entry, err := kv.KeyValue.GetRevision(key, revision)
+	kv.observeOperation(collectors.NatsKeyValueGetOperation, startTime, err)
+
+	return entry, err
+}
+
+func (kv *instrumentedKeyValue) Put(key string, value []byte) (uint64, error) {
+	startTime := time.Now()
+	revision, err := kv.KeyValue.Put(key, value)
+	kv.observeOperation(collectors.NatsKeyValuePutOperation, startTime, err)
+
+	return revision, err
+}
+
+func (kv *instrumentedKeyValue) PutString(key string, value string) (uint64, error) {
+	return kv.Put(key, []byte(value))
+}
+
+func (kv *instrumentedKeyValue) Create(key string, value []byte) (uint64, error) {
+	startTime := time.Now()
+	revision, err := kv.KeyValue.Create(key, value)
+	kv.observeOperation(collectors.NatsKeyValueCreateOperation, startTime, err)
+
+	return revision, err
+}
+
+func (kv *instrumentedKeyValue) Update(key string, value []byte, last uint64) (uint64, error) {
+	startTime := time.Now()
+	revision, err := kv.KeyValue.Update(key, value, last)
+	kv.observeOperation(collectors.NatsKeyValueUpdateOperation, startTime, err)
+
+	return revision, err
+}
+
+func (kv *instrumentedKeyValue) Delete(key string, opts ...nats.DeleteOpt) error {
+	startTime := time.Now()
+	err := kv.KeyValue.Delete(key, opts...)
+	kv.observeOperation(collectors.NatsKeyValueDeleteOperation, startTime, err)
+
+	return err
+}
+
+func (kv *instrumentedKeyValue) Purge(key string, opts ...nats.DeleteOpt) error {
+	startTime := time.Now()
+	err := kv.KeyValue.Purge(key, opts...)
+	kv.observeOperation(collectors.NatsKeyValuePurgeOperation, startTime, err)
+
+	return err
+}
+
+func (kv *instrumentedKeyValue) Watch(keys string, opts ...nats.WatchOpt) (nats.KeyWatcher, error) {
+	watcher, err := kv.KeyValue.Watch(keys, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstrumentedKeyWatcher(kv.Bucket(), watcher), nil
+}
+
+func (kv *instrumentedKeyValue) WatchAll(opts ...nats.WatchOpt) (nats.KeyWatcher, error) {
+	watcher, err := kv.KeyValue.WatchAll(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstrumentedKeyWatcher(kv.Bucket(), watcher), nil
+}
+
+func (kv *instrumentedKeyValue) observeOperation(operation string, startTime time.Time, err error) {
+	kc, kcErr := collectors.GetNatsKeyValueMetricsCollector()
+	if kcErr != nil {
+		panic(kcErr)
+	}
+	outcome := keyValueOutcome(err)
+
+	kc.IncOperationCount(kv.Bucket(), operation, outcome)
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	kc.ObserveOperationDuration(kv.Bucket(), operation, outcome, elapsed)
+}
+
+type instrumentedKeyWatcher struct {
+	nats.KeyWatcher
+	updates  chan nats.KeyValueEntry
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newInstrumentedKeyWatcher(bucket string, watcher nats.KeyWatcher) *instrumentedKeyWatcher {
+	kc, err := collectors.GetNatsKeyValueMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	instrumentedWatcher := &instrumentedKeyWatcher{
+		KeyWatcher: watcher,
+		updates:    make(chan nats.KeyValueEntry, cap(watcher.Updates())),
+		stop:       make(chan struct{}),
+	}
+	startTime := time.Now()
+
+	go func() {
+		defer close(instrumentedWatcher.updates)
+
+		// The watcher replays the initial values before sending a nil entry. Their lag reflects
+		// the age of the values rather than delivery delays, so it is only observed for live updates,
+		// which are the entries after the nil entry or created after the watcher was started. The
+		// latter covers watchers using nats.UpdatesOnly, which never send the nil entry.
+		initialized := false
+		for {
+			var entry nats.KeyValueEntry
+			select {
+			case update, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+				entry = update
+			case <-instrumentedWatcher.stop:
+				return
+			}
+
+			if entry == nil {
+				initialized = true
+			} else {
+				kc.IncWatcherUpdateCount(bucket, keyValueOperation(entry.Operation()))
+				if initialized || entry.Created().After(startTime) {
+					kc.ObserveWatcherLag(bucket, time.Since(entry.Created()).Seconds())
+				}
+			}
+
+			select {
+			case instrumentedWatcher.updates <- entry:
+			case <-instrumentedWatcher.stop:
+				return
+			}
+		}
+	}()
+
+	return instrumentedWatcher
+}
+
+func (w *instrumentedKeyWatcher) Updates() <-chan nats.KeyValueEntry {
+	return w.updates
+}
+
+func (w *instrumentedKeyWatcher) Stop() error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+
+	return w.KeyWatcher.Stop()
+}
+
+func keyValueOperation(op nats.KeyValueOp) string {
+	switch op {
+	case nats.KeyValueDelete:
+		return collectors.NatsKeyValueDeleteOperation
+	case nats.KeyValuePurge:
+		return collectors.NatsKeyValuePurgeOperation
+	default:
+		return collectors.NatsKeyValuePutOperation
+	}
+}
+
+func keyValueOutcome(err error) string {
+	var apiErr *nats.APIError
+
+	switch {
+	case err == nil:
+		return collectors.NatsKeyValueOutcomeOk
+	case errors.Is(err, nats.ErrKeyNotFound):
+		return collectors.NatsKeyValueOutcomeKeyNotFound
+	case errors.As(err, &apiErr) && apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence:
+		return collectors.NatsKeyValueOutcomeWrongLastSequence
+	case errors.Is(err, nats.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return collectors.NatsKeyValueOutcomeTimeout
+	default:
+		return collectors.NatsKeyValueOutcomeError
+	}
+}
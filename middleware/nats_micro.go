@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
+)
+
+// instrumentedMicroRequest records whether the handler answered the request with an error.
+type instrumentedMicroRequest struct {
+	micro.Request
+	failed bool
+}
+
+func (r *instrumentedMicroRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	r.failed = true
+
+	return r.Request.Error(code, description, data, opts...)
+}
+
+// WrapMicroHandler instruments a micro service endpoint handler with the processed message
+// metrics, labeled by the request subject and the micro message type. Requests answered with an
+// error are counted as handler errors.
+func WrapMicroHandler(handler micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(req micro.Request) {
+		mc, err := collectors.GetNatsMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		hc, err := collectors.GetNatsHandlerMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		hl, err := collectors.GetHeaderLabeler()
+		if err != nil {
+			panic(err)
+		}
+		atc, err := collectors.GetNatsActivityMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.Normalize(req.Subject())
+		header := nats.Header(req.Headers())
+		headerLabels := hl.Values(header)
+		instrumentedReq := &instrumentedMicroRequest{Request: req}
+		startTime := time.Now()
+
+		handler.Handle(instrumentedReq)
+
+		mc.IncProcessedMessageCount(subject, collectors.NatsMicroMessageType, headerLabels...)
+		atc.SetLastProcessedMessageTime(subject, time.Now())
+		if instrumentedReq.failed {
+			hc.IncHandlerErrorCount(subject, collectors.NatsMicroMessageType)
+		}
+
+		size := float64(len(req.Data()) + headerSize(header))
+		mc.ObserveProcessedMessageSize(subject, collectors.NatsMicroMessageType, size, headerLabels...)
+		mc.AddProcessedMessageBytes(subject, collectors.NatsMicroMessageType, size, headerLabels...)
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		mc.ObserveMessageProcessingDuration(subject, collectors.NatsMicroMessageType, elapsed, headerLabels...)
+	})
+}
+
+// TrackMicroService exports the endpoint stats and the info of a micro service until it is
+// stopped.
+func TrackMicroService(svc micro.Service) {
+	mc, err := collectors.GetNatsMicroMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	mc.TrackService(svc)
+}
@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const unknownBucketLabel = "unknown"
+
+// errObjectReadAborted records Gets whose result was closed before the object was fully read.
+var errObjectReadAborted = errors.New("object result closed before the object was fully read")
+
+type instrumentedObjectStore struct {
+	nats.ObjectStore
+	bucket string
+}
+
+// WrapObjectStore instruments the Put, Get and Delete operations of an object store, including
+// the convenience variants for bytes, strings and files. PutBytes, PutString and GetString go
+// through the instrumented Put and GetBytes, while GetBytes, GetFile and PutFile keep their
+// upstream implementations. The bucket name is looked up once from the store status.
+func WrapObjectStore(obs nats.ObjectStore) nats.ObjectStore {
+	bucket := unknownBucketLabel
+	if status, err := obs.Status(); err == nil {
+		bucket = status.Bucket()
+	}
+
+	return &instrumentedObjectStore{ObjectStore: obs, bucket: bucket}
+}
+
+func (obs *instrumentedObjectStore) Put(meta *nats.ObjectMeta, reader io.Reader, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	startTime := time.Now()
+	info, err := obs.ObjectStore.Put(meta, reader, opts...)
+	if err != nil {
+		obs.observeOperation(collectors.NatsObjectStorePutOperation, startTime, err)
+		return nil, err
+	}
+
+	obs.observeTransfer(collectors.NatsObjectStorePutOperation, startTime, info.Size)
+	obs.observeChunks(collectors.NatsObjectStorePutOperation, info.Chunks)
+
+	return info, nil
+}
+
+func (obs *instrumentedObjectStore) PutBytes(name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	return obs.Put(&nats.ObjectMeta{Name: name}, bytes.NewReader(data), opts...)
+}
+
+func (obs *instrumentedObjectStore) PutString(name string, data string, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	return obs.Put(&nats.ObjectMeta{Name: name}, strings.NewReader(data), opts...)
+}
+
+func (obs *instrumentedObjectStore) PutFile(file string, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	startTime := time.Now()
+	info, err := obs.ObjectStore.PutFile(file, opts...)
+	if err != nil {
+		obs.observeOperation(collectors.NatsObjectStorePutOperation, startTime, err)
+		return nil, err
+	}
+
+	obs.observeTransfer(collectors.NatsObjectStorePutOperation, startTime, info.Size)
+	obs.observeChunks(collectors.NatsObjectStorePutOperation, info.Chunks)
+
+	return info, nil
+}
+
+// Get returns a result that records the transfer once the object has been read or closed.
+func (obs *instrumentedObjectStore) Get(name string, opts ...nats.GetObjectOpt) (nats.ObjectResult, error) {
+	startTime := time.Now()
+	result, err := obs.ObjectStore.Get(name, opts...)
+	if err != nil {
+		obs.observeOperation(collectors.NatsObjectStoreGetOperation, startTime, err)
+		return nil, err
+	}
+
+	return &instrumentedObjectResult{ObjectResult: result, store: obs, startTime: startTime}, nil
+}
+
+// GetBytes records the transfer without the chunk count, which the upstream implementation does
+// not expose.
+func (obs *instrumentedObjectStore) GetBytes(name string, opts ...nats.GetObjectOpt) ([]byte, error) {
+	startTime := time.Now()
+	data, err := obs.ObjectStore.GetBytes(name, opts...)
+	if err != nil {
+		obs.observeOperation(collectors.NatsObjectStoreGetOperation, startTime, err)
+		return nil, err
+	}
+
+	obs.observeTransfer(collectors.NatsObjectStoreGetOperation, startTime, uint64(len(data)))
+
+	return data, nil
+}
+
+func (obs *instrumentedObjectStore) GetString(name string, opts ...nats.GetObjectOpt) (string, error) {
+	data, err := obs.GetBytes(name, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// GetFile records the transfer without the chunk count, which the upstream implementation does
+// not expose. The size is taken from the written file.
+func (obs *instrumentedObjectStore) GetFile(name, file string, opts ...nats.GetObjectOpt) error {
+	startTime := time.Now()
+	if err := obs.ObjectStore.GetFile(name, file, opts...); err != nil {
+		obs.observeOperation(collectors.NatsObjectStoreGetOperation, startTime, err)
+		return err
+	}
+
+	var size uint64
+	if stat, err := os.Stat(file); err == nil {
+		size = uint64(stat.Size())
+	}
+	obs.observeTransfer(collectors.NatsObjectStoreGetOperation, startTime, size)
+
+	return nil
+}
+
+func (obs *instrumentedObjectStore) Delete(name string) error {
+	startTime := time.Now()
+	err := obs.ObjectStore.Delete(name)
+	obs.observeOperation(collectors.NatsObjectStoreDeleteOperation, startTime, err)
+
+	return err
+}
+
+func (obs *instrumentedObjectStore) observeOperation(operation string, startTime time.Time, err error) {
+	oc, ocErr := collectors.GetNatsObjectStoreMetricsCollector()
+	if ocErr != nil {
+		panic(ocErr)
+	}
+	outcome := objectStoreOutcome(err)
+
+	oc.IncOperationCount(obs.bucket, operation, outcome)
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	oc.ObserveOperationDuration(obs.bucket, operation, outcome, elapsed)
+}
+
+func (obs *instrumentedObjectStore) observeTransfer(operation string, startTime time.Time, size uint64) {
+	oc, err := collectors.GetNatsObjectStoreMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	obs.observeOperation(operation, startTime, nil)
+
+	oc.AddTransferredBytes(obs.bucket, operation, float64(size))
+
+	if elapsed := time.Since(startTime).Seconds(); elapsed > 0 {
+		oc.ObserveThroughput(obs.bucket, operation, float64(size)/elapsed)
+	}
+}
+
+func (obs *instrumentedObjectStore) observeChunks(operation string, chunks uint32) {
+	oc, err := collectors.GetNatsObjectStoreMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	oc.ObserveChunkCount(obs.bucket, operation, float64(chunks))
+}
+
+// instrumentedObjectResult records a Get operation when the object has been fully read, reading
+// it failed, or the result is closed, whichever happens first. Results closed before the object
+// has been fully read are recorded as aborted.
+type instrumentedObjectResult struct {
+	nats.ObjectResult
+	store     *instrumentedObjectStore
+	startTime time.Time
+	read      uint64
+	once      sync.Once
+}
+
+func (r *instrumentedObjectResult) Read(p []byte) (int, error) {
+	n, err := r.ObjectResult.Read(p)
+	r.read += uint64(n)
+
+	if errors.Is(err, io.EOF) {
+		r.observe(nil)
+	} else if err != nil {
+		r.observe(err)
+	}
+
+	return n, err
+}
+
+func (r *instrumentedObjectResult) Close() error {
+	err := r.ObjectResult.Close()
+
+	// Readers such as io.ReadFull stop after the size of the object without reading EOF
+	if info, infoErr := r.Info(); infoErr == nil && r.read >= info.Size {
+		r.observe(nil)
+	} else {
+		r.observe(errObjectReadAborted)
+	}
+
+	return err
+}
+
+func (r *instrumentedObjectResult) observe(err error) {
+	r.once.Do(func() {
+		if err != nil {
+			r.store.observeOperation(collectors.NatsObjectStoreGetOperation, r.startTime, err)
+			return
+		}
+
+		r.store.observeTransfer(collectors.NatsObjectStoreGetOperation, r.startTime, r.read)
+		if info, infoErr := r.Info(); infoErr == nil {
+			r.store.observeChunks(collectors.NatsObjectStoreGetOperation, info.Chunks)
+		}
+	})
+}
+
+func objectStoreOutcome(err error) string {
+	switch {
+	case err == nil:
+		return collectors.NatsObjectStoreOutcomeOk
+	case errors.Is(err, nats.ErrObjectNotFound):
+		return collectors.NatsObjectStoreOutcomeObjectNotFound
+	case errors.Is(err, errObjectReadAborted):
+		return collectors.NatsObjectStoreOutcomeAborted
+	default:
+		return collectors.NatsObjectStoreOutcomeError
+	}
+}
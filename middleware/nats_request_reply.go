@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"time"
+)
+
+func WrapRequest(nc *nats.Conn) func(string, []byte, time.Duration) (*nats.Msg, error) {
+	return func(subject string, data []byte, timeout time.Duration) (*nats.Msg, error) {
+		rc, err := collectors.GetNatsRequestReplyMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		startTime := time.Now()
+		reply, err := nc.Request(subject, data, timeout)
+
+		observeRequest(rc, sn.Normalize(subject), startTime, err)
+
+		return reply, err
+	}
+}
+
+func WrapRequestWithContext(nc *nats.Conn) func(context.Context, string, []byte) (*nats.Msg, error) {
+	return func(ctx context.Context, subject string, data []byte) (*nats.Msg, error) {
+		rc, err := collectors.GetNatsRequestReplyMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		startTime := time.Now()
+		reply, err := nc.RequestWithContext(ctx, subject, data)
+
+		observeRequest(rc, sn.Normalize(subject), startTime, err)
+
+		return reply, err
+	}
+}
+
+// WrapRespondMessage wraps a request handler returning the response payload. The handler
+// and the sending of the response are timed separately, and failed responses are counted.
+func WrapRespondMessage(funcToWrap func(*nats.Msg) []byte) func(*nats.Msg) {
+	return func(msg *nats.Msg) {
+		rc, err := collectors.GetNatsRequestReplyMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.MessageSubject(msg)
+		startTime := time.Now()
+		response := funcToWrap(msg)
+
+		rc.IncRespondedRequestCount(subject)
+
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		rc.ObserveResponderHandlingDuration(subject, elapsed)
+
+		startTime = time.Now()
+		err = msg.Respond(response)
+		if err != nil {
+			rc.IncRespondErrorCount(subject)
+			return
+		}
+
+		elapsed = float64(time.Since(startTime).Nanoseconds()) / 1e9
+		rc.ObserveRespondDuration(subject, elapsed)
+	}
+}
+
+func observeRequest(rc collectors.RequestReplyMetricsCollector, subject string, startTime time.Time, err error) {
+	outcome := requestOutcome(err)
+
+	rc.IncRequestCount(subject, outcome)
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	rc.ObserveRequestDuration(subject, outcome, elapsed)
+}
+
+func requestOutcome(err error) string {
+	switch {
+	case err == nil:
+		return collectors.NatsRequestOutcomeOk
+	case errors.Is(err, nats.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return collectors.NatsRequestOutcomeTimeout
+	case errors.Is(err, nats.ErrNoResponders):
+		return collectors.NatsRequestOutcomeNoResponders
+	default:
+		return collectors.NatsRequestOutcomeError
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+)
+
+// Subscribe creates a subscription whose handler is wrapped with WrapProcessMessage and
+// whose pending, dropped and slow consumer metrics are tracked.
+func Subscribe(nc *nats.Conn, subject string, funcToWrap func(*nats.Msg)) (*nats.Subscription, error) {
+	sub, err := nc.Subscribe(subject, WrapProcessMessage(funcToWrap))
+	if err != nil {
+		return nil, err
+	}
+
+	TrackSubscription(nc, sub)
+
+	return sub, nil
+}
+
+// QueueSubscribe creates a queue subscription whose handler is wrapped with WrapProcessMessage
+// and whose pending, dropped and slow consumer metrics are tracked.
+func QueueSubscribe(nc *nats.Conn, subject, queue string, funcToWrap func(*nats.Msg)) (*nats.Subscription, error) {
+	sub, err := nc.QueueSubscribe(subject, queue, WrapProcessMessage(funcToWrap))
+	if err != nil {
+		return nil, err
+	}
+
+	TrackSubscription(nc, sub)
+
+	return sub, nil
+}
+
+// TrackSubscription tracks the pending, dropped and slow consumer metrics of a subscription
+// created on the given connection until it is unsubscribed.
+func TrackSubscription(nc *nats.Conn, sub *nats.Subscription) {
+	sc, err := collectors.GetNatsSubscriptionMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	sc.TrackSubscription(nc, sub, sub.Subject)
+}
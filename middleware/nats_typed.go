@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"reflect"
+	"time"
+)
+
+// WrapTyped decodes message payloads into values of type T before calling the handler. The
+// returned handler is meant to be wrapped with WrapProcessMessageWithContext or
+// WrapProcessJetStreamMessageWithContext, so decoding is recorded separately from the handler.
+// Payloads that fail to be decoded are returned as errors without calling the handler.
+func WrapTyped[T any](codec Codec, funcToWrap func(ctx context.Context, value T, msg *nats.Msg) error) ContextHandler {
+	return func(ctx context.Context, msg *nats.Msg) error {
+		cc, err := collectors.GetNatsCodecMetricsCollector()
+		if err != nil {
+			panic(err)
+		}
+		sn, err := collectors.GetSubjectNormalizer()
+		if err != nil {
+			panic(err)
+		}
+		subject := sn.MessageSubject(msg)
+
+		// Pointer types are allocated, since codecs such as protobuf cannot decode into nil
+		var value T
+		target := any(&value)
+		if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() == reflect.Pointer {
+			value = reflect.New(t.Elem()).Interface().(T)
+			target = value
+		}
+
+		startTime := time.Now()
+		err = codec.Unmarshal(msg.Data, target)
+		elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+		cc.ObserveDecodeDuration(subject, codec.Name(), elapsed)
+		if err != nil {
+			cc.IncDecodeErrorCount(subject, codec.Name())
+			return err
+		}
+
+		return funcToWrap(ctx, value, msg)
+	}
+}
+
+// PublishTyped encodes values of type T and publishes them with an instrumented publisher such
+// as the one returned by WrapPublishMsg. Values that fail to be encoded are not published.
+func PublishTyped[T any](codec Codec, publish func(*nats.Msg) error) func(subject string, value T) error {
+	return func(subject string, value T) error {
+		msg, err := encodeTyped(codec, subject, value)
+		if err != nil {
+			return err
+		}
+
+		return publish(msg)
+	}
+}
+
+// PublishJetStreamTyped is the equivalent of PublishTyped for instrumented JetStream publishers
+// such as the one returned by WrapPublishJetStreamMsg.
+func PublishJetStreamTyped[T any](codec Codec, publish func(*nats.Msg, ...nats.PubOpt) (*nats.PubAck, error)) func(subject string, value T, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	return func(subject string, value T, opts ...nats.PubOpt) (*nats.PubAck, error) {
+		msg, err := encodeTyped(codec, subject, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return publish(msg, opts...)
+	}
+}
+
+func encodeTyped(codec Codec, subject string, value any) (*nats.Msg, error) {
+	cc, err := collectors.GetNatsCodecMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	subjectLabel := sn.Normalize(subject)
+
+	startTime := time.Now()
+	data, err := codec.Marshal(value)
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	cc.ObserveEncodeDuration(subjectLabel, codec.Name(), elapsed)
+	if err != nil {
+		cc.IncEncodeErrorCount(subjectLabel, codec.Name())
+		return nil, err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+
+	return msg, nil
+}
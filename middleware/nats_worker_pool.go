@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"sync"
+	"time"
+)
+
+type receivedMsg struct {
+	msg        *nats.Msg
+	receivedAt time.Time
+}
+
+// RunWorkers pulls messages from a synchronous or queue subscription, including JetStream
+// subscriptions created with SubscribeSync or QueueSubscribeSync, and processes them with the
+// given number of workers. The handler is expected to be wrapped with WrapProcessMessage or
+// WrapProcessJetStreamMessage.
+//
+// RunWorkers blocks until the context is cancelled and returns nil once the in-flight messages
+// have been processed. Idle subscriptions do not time out, and nats.ErrTimeout is ignored. Any
+// other error of the subscription, such as it being closed, stops the workers and is returned.
+func RunWorkers(ctx context.Context, sub *nats.Subscription, workers int, handler func(*nats.Msg)) error {
+	wc, err := collectors.GetNatsWorkerPoolMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	subject := sn.Normalize(sub.Subject)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	wc.AddWorkers(subject, sub.Queue, workers)
+	defer wc.AddWorkers(subject, sub.Queue, -workers)
+
+	// Unbuffered, so messages are only received once a worker is free
+	msgs := make(chan receivedMsg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for received := range msgs {
+				elapsed := float64(time.Since(received.receivedAt).Nanoseconds()) / 1e9
+				wc.ObserveQueueWaitDuration(subject, sub.Queue, elapsed)
+
+				wc.IncInFlightMessages(subject, sub.Queue)
+				handler(received.msg)
+				wc.DecInFlightMessages(subject, sub.Queue)
+			}
+		}()
+	}
+
+	err = receiveMessages(ctx, sub, msgs)
+	close(msgs)
+	wg.Wait()
+
+	return err
+}
+
+func receiveMessages(ctx context.Context, sub *nats.Subscription, msgs chan<- receivedMsg) error {
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, nats.ErrTimeout) {
+				continue
+			}
+			return err
+		}
+
+		select {
+		case msgs <- receivedMsg{msg: msg, receivedAt: time.Now()}:
+		case <-ctx.Done():
+			// The message is left unprocessed and will be redelivered for JetStream subscriptions
+			return nil
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import "time"
+
+const defaultAckTimeout = 30 * time.Second
+
+// PublishOption configures the behaviour of the instrumented publishing wrappers.
+type PublishOption func(*publishOptions)
+
+type publishOptions struct {
+	stampHeaders bool
+	ackTimeout   time.Duration
+	retryPolicy  *RetryPolicy
+	breaker      *CircuitBreaker
+}
+
+func newPublishOptions(opts []PublishOption) *publishOptions {
+	o := &publishOptions{
+		ackTimeout: defaultAckTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithPublishTimestamp stamps every published message with the publish time and the
+// service name, allowing WrapProcessMessage to measure end-to-end latency on the
+// subscriber side.
+func WithPublishTimestamp() PublishOption {
+	return func(o *publishOptions) {
+		o.stampHeaders = true
+	}
+}
+
+// WithAckTimeout bounds how long the asynchronous JetStream publishing wrappers wait for an
+// acknowledgement before counting the publish as timed out. Defaults to 30 seconds, which is
+// kept for non-positive timeouts.
+func WithAckTimeout(timeout time.Duration) PublishOption {
+	return func(o *publishOptions) {
+		if timeout > 0 {
+			o.ackTimeout = timeout
+		}
+	}
+}
+
+// WithRetryPolicy retries synchronous JetStream publishes failing with a retryable error
+// according to the policy. Publish options such as nats.MsgId are passed to every attempt, so
+// that retries of a publish that reached the stream are deduplicated. Messages without a message
+// ID are published with a generated one, shared by all attempts.
+func WithRetryPolicy(policy RetryPolicy) PublishOption {
+	return func(o *publishOptions) {
+		o.retryPolicy = policy.withDefaults()
+	}
+}
+
+// WithCircuitBreaker guards the publishes with the circuit breaker, failing them fast with
+// ErrCircuitOpen while the circuit is open. Retried JetStream publishes count as one publish.
+func WithCircuitBreaker(breaker *CircuitBreaker) PublishOption {
+	return func(o *publishOptions) {
+		o.breaker = breaker
+	}
+}
+
+// AdvisoryOption configures the behaviour of the JetStream advisory subscriber.
+type AdvisoryOption func(*advisoryOptions)
+
+type advisoryOptions struct {
+	deadLetterSubject string
+	queueGroup        string
+}
+
+func newAdvisoryOptions(opts []AdvisoryOption) *advisoryOptions {
+	o := &advisoryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithDeadLetterSubject republishes the advisories of messages that exceeded their maximum
+// deliveries or were terminated to the given subject.
+func WithDeadLetterSubject(subject string) AdvisoryOption {
+	return func(o *advisoryOptions) {
+		o.deadLetterSubject = subject
+	}
+}
+
+// WithAdvisoryQueueGroup subscribes to the advisories in the given queue group, so that replicas
+// of a service count and republish every advisory once.
+func WithAdvisoryQueueGroup(queue string) AdvisoryOption {
+	return func(o *advisoryOptions) {
+		o.queueGroup = queue
+	}
+}
+
+// HandlerOption configures the behaviour of the instrumented message handler wrappers.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	timeout           time.Duration
+	heartbeatInterval time.Duration
+	hardLimit         time.Duration
+}
+
+func newHandlerOptions(opts []HandlerOption) *handlerOptions {
+	o := &handlerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithHandlerTimeout sets the deadline of the context passed to context-aware handlers,
+// overriding the AckWait of the JetStream consumer.
+func WithHandlerTimeout(timeout time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithInProgressHeartbeat acknowledges JetStream messages as in progress every interval while
+// their handler is running, preventing redeliveries of handlers running longer than AckWait.
+func WithInProgressHeartbeat(interval time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.heartbeatInterval = interval
+	}
+}
+
+// WithHardLimit stops the in-progress heartbeats of handlers running longer than the limit,
+// letting the message be redelivered once AckWait expires, and counts them.
+func WithHardLimit(limit time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.hardLimit = limit
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithAckTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{"positive", 5 * time.Second, 5 * time.Second},
+		{"zero keeps default", 0, defaultAckTimeout},
+		{"negative keeps default", -time.Second, defaultAckTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := newPublishOptions([]PublishOption{WithAckTimeout(tt.timeout)})
+			if options.ackTimeout != tt.want {
+				t.Errorf("ackTimeout = %v, want %v", options.ackTimeout, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+)
+
+// RetryPolicy configures the retries of JetStream publishes enabled with WithRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of publish attempts, including the first one,
+	// defaulting to 3.
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry, defaulting to 100 milliseconds.
+	// It doubles for every further retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff between retries, defaulting to 2 seconds.
+	MaxBackoff time.Duration
+	// Retryable classifies the errors worth retrying, defaulting to IsRetryablePublishError.
+	Retryable func(error) bool
+	// Done aborts the backoff between retries once closed, e.g. with the Done channel of the
+	// shutdown context, in which case the last error is returned.
+	Done <-chan struct{}
+}
+
+func (p RetryPolicy) withDefaults() *RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryablePublishError
+	}
+
+	return &p
+}
+
+// backoff returns the backoff before the given retry, starting at 1, with jitter of up to half
+// of the exponential backoff so that publishers do not retry in lockstep.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// IsRetryablePublishError reports whether a JetStream publish failed transiently, such as
+// during a stream leader election, and is worth retrying.
+func IsRetryablePublishError(err error) bool {
+	return errors.Is(err, nats.ErrTimeout) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, nats.ErrNoResponders) ||
+		errors.Is(err, nats.ErrNoStreamResponse)
+}
+
+// publishJetStreamMsgWithRetry publishes the message with publishJetStreamMsg, retrying failed
+// attempts according to the retry policy of the options.
+func publishJetStreamMsgWithRetry(js nats.JetStreamContext, msg *nats.Msg, options *publishOptions, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	policy := options.retryPolicy
+	if policy == nil {
+		return publishJetStreamMsg(js, msg, opts...)
+	}
+
+	rc, err := collectors.GetNatsPublishRetryMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	subjectLabel := sn.Normalize(msg.Subject)
+
+	// Retries of an attempt that was stored but timed out are only deduplicated with a message ID
+	if msg.Header.Get(nats.MsgIdHdr) == "" {
+		msg = withMsgId(msg, nuid.Next())
+	}
+
+	for attempt := 1; ; attempt++ {
+		rc.IncPublishAttemptCount(subjectLabel)
+
+		ack, err := publishJetStreamMsg(js, msg, opts...)
+		if err == nil {
+			return ack, nil
+		}
+
+		reason := publishErrorReason(err)
+		if attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			rc.IncPublishGiveUpCount(subjectLabel, reason)
+			return nil, err
+		}
+
+		if !policy.wait(policy.backoff(attempt)) {
+			rc.IncPublishGiveUpCount(subjectLabel, reason)
+			return nil, err
+		}
+		rc.IncPublishRetryCount(subjectLabel, reason)
+	}
+}
+
+// wait waits for the backoff, returning false when the policy is done first.
+func (p *RetryPolicy) wait(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-p.Done:
+		return false
+	}
+}
+
+// withMsgId returns a copy of the message with the message ID header set, leaving the message of
+// the caller untouched.
+func withMsgId(msg *nats.Msg, id string) *nats.Msg {
+	header := make(nats.Header, len(msg.Header)+1)
+	for key, values := range msg.Header {
+		header[key] = values
+	}
+	header.Set(nats.MsgIdHdr, id)
+
+	return &nats.Msg{Subject: msg.Subject, Reply: msg.Reply, Header: header, Data: msg.Data}
+}
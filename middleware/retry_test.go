@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"github.com/nats-io/nats.go"
+	"testing"
+	"time"
+)
+
+// flakyJetStream fails the first publishes with nats.ErrTimeout and records the message ID of
+// every attempt.
+type flakyJetStream struct {
+	nats.JetStreamContext
+	failures int
+	ids      []string
+}
+
+func (js *flakyJetStream) PublishMsg(msg *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	js.ids = append(js.ids, msg.Header.Get(nats.MsgIdHdr))
+	if len(js.ids) <= js.failures {
+		return nil, nats.ErrTimeout
+	}
+
+	return &nats.PubAck{Stream: "ORDERS", Sequence: 1}, nil
+}
+
+func TestWrapPublishJetStreamMessageRetriesWithSameMsgId(t *testing.T) {
+	newTestCollectors()
+
+	js := &flakyJetStream{failures: 2}
+	publish := WrapPublishJetStreamMessage(js, WithRetryPolicy(RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}))
+
+	if err := publish("orders.created", []byte("{}")); err != nil {
+		t.Fatalf("publish() error = %v", err)
+	}
+
+	if len(js.ids) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(js.ids))
+	}
+	if js.ids[0] == "" {
+		t.Fatal("first attempt has no message ID")
+	}
+	for i, id := range js.ids {
+		if id != js.ids[0] {
+			t.Errorf("attempt %d message ID = %q, want %q", i+1, id, js.ids[0])
+		}
+	}
+}
+
+func TestWrapPublishJetStreamMsgKeepsMsgId(t *testing.T) {
+	newTestCollectors()
+
+	js := &flakyJetStream{failures: 1}
+	publish := WrapPublishJetStreamMsg(js, WithRetryPolicy(RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}))
+
+	msg := nats.NewMsg("orders.created")
+	msg.Header.Set(nats.MsgIdHdr, "order-42")
+	if _, err := publish(msg); err != nil {
+		t.Fatalf("publish() error = %v", err)
+	}
+
+	for i, id := range js.ids {
+		if id != "order-42" {
+			t.Errorf("attempt %d message ID = %q, want %q", i+1, id, "order-42")
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+	drainPollInterval   = 10 * time.Millisecond
+)
+
+// SubscriptionManager owns instrumented subscriptions created on a NATS connection and drains
+// them when the Fiber app shuts down.
+type SubscriptionManager struct {
+	nc           *nats.Conn
+	drainTimeout time.Duration
+
+	mu            sync.Mutex
+	subscriptions []*nats.Subscription
+	draining      atomic.Bool
+}
+
+// NewSubscriptionManager creates a subscription manager whose subscriptions are drained by the
+// OnShutdown hook of the app, waiting at most drainTimeout, which defaults to 30 seconds.
+func NewSubscriptionManager(app *fiber.App, nc *nats.Conn, drainTimeout time.Duration) *SubscriptionManager {
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	m := &SubscriptionManager{nc: nc, drainTimeout: drainTimeout}
+	app.Hooks().OnShutdown(m.Drain)
+
+	return m
+}
+
+// Subscribe creates a managed subscription whose handler is wrapped with WrapProcessMessage.
+func (m *SubscriptionManager) Subscribe(subject string, funcToWrap func(*nats.Msg)) (*nats.Subscription, error) {
+	sub, err := m.nc.Subscribe(subject, m.countDrainedMessages(subject, "", WrapProcessMessage(funcToWrap)))
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(sub), nil
+}
+
+// QueueSubscribe creates a managed queue subscription whose handler is wrapped with
+// WrapProcessMessage.
+func (m *SubscriptionManager) QueueSubscribe(subject, queue string, funcToWrap func(*nats.Msg)) (*nats.Subscription, error) {
+	sub, err := m.nc.QueueSubscribe(subject, queue, m.countDrainedMessages(subject, queue, WrapProcessMessage(funcToWrap)))
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(sub), nil
+}
+
+// JetStreamSubscribe creates a managed JetStream push subscription whose handler is wrapped
+// with WrapProcessJetStreamMessage.
+func (m *SubscriptionManager) JetStreamSubscribe(js nats.JetStreamContext, subject string, funcToWrap func(*nats.Msg), opts ...nats.SubOpt) (*nats.Subscription, error) {
+	sub, err := js.Subscribe(subject, m.countDrainedMessages(subject, "", WrapProcessJetStreamMessage(funcToWrap)), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(sub), nil
+}
+
+// JetStreamQueueSubscribe creates a managed JetStream push queue subscription whose handler is
+// wrapped with WrapProcessJetStreamMessage.
+func (m *SubscriptionManager) JetStreamQueueSubscribe(js nats.JetStreamContext, subject, queue string, funcToWrap func(*nats.Msg), opts ...nats.SubOpt) (*nats.Subscription, error) {
+	sub, err := js.QueueSubscribe(subject, queue, m.countDrainedMessages(subject, queue, WrapProcessJetStreamMessage(funcToWrap)), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(sub), nil
+}
+
+// JetStreamPullSubscribe creates a managed JetStream pull subscription. Messages fetched from
+// pull subscriptions are not counted as processed during drain.
+func (m *SubscriptionManager) JetStreamPullSubscribe(js nats.JetStreamContext, subject, durable string, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	sub, err := js.PullSubscribe(subject, durable, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(sub), nil
+}
+
+// Add hands an existing subscription created on the connection of the manager over to it, and
+// tracks it like TrackSubscription.
+func (m *SubscriptionManager) Add(sub *nats.Subscription) *nats.Subscription {
+	smc, err := collectors.GetNatsSubscriptionManagerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+
+	m.mu.Lock()
+	m.subscriptions = append(m.subscriptions, sub)
+	m.mu.Unlock()
+
+	smc.AddSubscription(sub, sub.Subject)
+	TrackSubscription(m.nc, sub)
+
+	return sub
+}
+
+// Drain drains all managed subscriptions, letting them process the messages already delivered.
+// Subscriptions that are not drained within the drain timeout are unsubscribed, and
+// nats.ErrDrainTimeout is returned.
+func (m *SubscriptionManager) Drain() error {
+	smc, err := collectors.GetNatsSubscriptionManagerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	startTime := time.Now()
+	m.draining.Store(true)
+
+	m.mu.Lock()
+	subscriptions := m.subscriptions
+	m.subscriptions = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for _, sub := range subscriptions {
+		if err := sub.Drain(); err != nil && !errors.Is(err, nats.ErrBadSubscription) {
+			errs = append(errs, err)
+		}
+	}
+
+	if remaining := waitForDrain(subscriptions, startTime.Add(m.drainTimeout)); len(remaining) > 0 {
+		smc.IncDrainTimeoutCount()
+		for _, sub := range remaining {
+			_ = sub.Unsubscribe()
+		}
+		errs = append(errs, nats.ErrDrainTimeout)
+	}
+
+	elapsed := float64(time.Since(startTime).Nanoseconds()) / 1e9
+	smc.ObserveDrainDuration(elapsed)
+
+	return errors.Join(errs...)
+}
+
+// countDrainedMessages counts the messages processed by a handler once the manager is draining.
+func (m *SubscriptionManager) countDrainedMessages(subject, queue string, handler func(*nats.Msg)) func(*nats.Msg) {
+	smc, err := collectors.GetNatsSubscriptionManagerMetricsCollector()
+	if err != nil {
+		panic(err)
+	}
+	sn, err := collectors.GetSubjectNormalizer()
+	if err != nil {
+		panic(err)
+	}
+	subjectLabel := sn.Normalize(subject)
+
+	return func(msg *nats.Msg) {
+		handler(msg)
+
+		if m.draining.Load() {
+			smc.IncDrainProcessedMessageCount(subjectLabel, queue)
+		}
+	}
+}
+
+// waitForDrain waits until the subscriptions are drained or the deadline passes, returning the
+// subscriptions that are still draining.
+func waitForDrain(subscriptions []*nats.Subscription, deadline time.Time) []*nats.Subscription {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var remaining []*nats.Subscription
+		for _, sub := range subscriptions {
+			if sub.IsValid() {
+				remaining = append(remaining, sub)
+			}
+		}
+
+		if len(remaining) == 0 || time.Now().After(deadline) {
+			return remaining
+		}
+		subscriptions = remaining
+
+		<-ticker.C
+	}
+}
@@ -3,20 +3,94 @@ package promnatsfiber
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/nats-io/nats.go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/todesdev/promnatsfiber/internal/collectors"
 	"github.com/todesdev/promnatsfiber/internal/registry"
 	"github.com/todesdev/promnatsfiber/middleware"
+	"time"
 )
 
+// NatsHeaderLabel adds a label to the processed and published NATS message metrics whose value
+// is read from a message header.
+type NatsHeaderLabel struct {
+	// Header is the name of the message header, e.g. "Event-Type".
+	Header string
+	// Label is the name of the label, e.g. "event_type".
+	Label string
+	// Default is the label value of messages without the header, defaulting to "unknown".
+	Default string
+	// MaxValues bounds the number of distinct label values, defaulting to 50. Values seen after
+	// the limit has been reached are replaced by "other".
+	MaxValues int
+}
+
 type Config struct {
 	FiberApp        *fiber.App
 	ServiceName     string
 	MetricsEndpoint string
+
+	// NatsSubjectPatterns bounds the cardinality of the subject label by mapping concrete
+	// subjects onto patterns using the * and > wildcards, e.g. "orders.*.created".
+	// Reply subjects starting with _INBOX. are always collapsed.
+	NatsSubjectPatterns []string
+	// NatsUseSubscriptionSubject labels processed messages with the subject filter of their
+	// subscription instead of the concrete message subject.
+	NatsUseSubscriptionSubject bool
+	// NatsConnections are the NATS connections whose health is exported. Their disconnect,
+	// reconnect, closed and async error handlers are wrapped to count connection events.
+	NatsConnections []*nats.Conn
+	// NatsRTTInterval is the interval between RTT measurements of the NatsConnections,
+	// defaulting to 30 seconds.
+	NatsRTTInterval time.Duration
+	// NatsJetStream is the JetStream context used to poll the state of streams and consumers.
+	// Polling is disabled when it is nil.
+	NatsJetStream nats.JetStreamContext
+	// NatsJetStreamStreams maps the streams whose state is exported to the names of their
+	// consumers to export. An empty list exports all consumers of the stream, and all streams
+	// are discovered when no streams are given.
+	NatsJetStreamStreams map[string][]string
+	// NatsJetStreamPollInterval is the interval between polls of the JetStream state,
+	// defaulting to 30 seconds.
+	NatsJetStreamPollInterval time.Duration
+	// NatsJetStreamPollTimeout bounds each JetStream state request, defaulting to 5 seconds.
+	NatsJetStreamPollTimeout time.Duration
+	// NatsHeaderLabels add labels read from message headers, such as the event type or the
+	// tenant, to the processed and published NATS message metrics.
+	NatsHeaderLabels []NatsHeaderLabel
+	// NatsIdleThreshold exports whether no message has been processed or published on a
+	// subject within the threshold. The idle gauge is disabled when it is zero.
+	NatsIdleThreshold time.Duration
+	// NatsExpectedProcessedSubjects and NatsExpectedPublishedSubjects are the subjects, as
+	// labeled after normalization, on which messages are expected to be processed or published.
+	// They are reported as idle from startup until their first message.
+	NatsExpectedProcessedSubjects []string
+	NatsExpectedPublishedSubjects []string
 }
 
 func New(config *Config) {
+	headerLabels := make([]collectors.HeaderLabel, 0, len(config.NatsHeaderLabels))
+	for _, label := range config.NatsHeaderLabels {
+		headerLabels = append(headerLabels, collectors.HeaderLabel(label))
+	}
+	if err := collectors.ValidateHeaderLabels(headerLabels); err != nil {
+		panic(err)
+	}
 
-	reg := registry.NewPrometheusRegistry(config.ServiceName, config.MetricsEndpoint)
+	reg := registry.NewPrometheusRegistry(config.ServiceName, config.MetricsEndpoint, registry.NatsOptions{
+		SubjectPatterns:        config.NatsSubjectPatterns,
+		UseSubscriptionSubject: config.NatsUseSubscriptionSubject,
+		Connections:            config.NatsConnections,
+		RTTInterval:            config.NatsRTTInterval,
+		JetStream:              config.NatsJetStream,
+		JetStreamStreams:       config.NatsJetStreamStreams,
+		JetStreamPollInterval:  config.NatsJetStreamPollInterval,
+		JetStreamPollTimeout:   config.NatsJetStreamPollTimeout,
+		HeaderLabels:           headerLabels,
+		IdleThreshold:          config.NatsIdleThreshold,
+		ExpectedProcessed:      config.NatsExpectedProcessedSubjects,
+		ExpectedPublished:      config.NatsExpectedPublishedSubjects,
+	})
 
 	// Set up the /metrics endpoint for Prometheus scraping using the custom registry
 	h := adaptor.HTTPHandler(promhttp.HandlerFor(reg.Registry, promhttp.HandlerOpts{}))